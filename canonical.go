@@ -0,0 +1,50 @@
+package qs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Canonicalize decodes qs with Unmarshal and encodes it again in canonical
+// form, so that semantically equal query strings compare equal.
+func Canonicalize(qs string) (string, error) {
+	hash, err := Unmarshal(qs)
+
+	if err != nil {
+		return "", err
+	}
+
+	return CanonicalizeParams(hash)
+}
+
+// CanonicalizeParams encodes hash with hash keys in sorted order, arrays
+// written as key[]=value and everything but unreserved characters and
+// brackets percent-encoded as in RFC 3986.
+func CanonicalizeParams(hash map[string]interface{}) (string, error) {
+	components := []string{}
+
+	err := flattenParams(hash, "", func(key string, value interface{}) error {
+		key = escapeFunc(key, isCanonicalKeyByte)
+
+		switch vv := value.(type) {
+		case nil:
+			components = append(components, key)
+		case string:
+			components = append(components, key+"="+escapeRFC3986(vv))
+		default:
+			return fmt.Errorf("Unsupported type '%T' for key '%s'", value, key)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(components, "&"), nil
+}
+
+func isCanonicalKeyByte(c byte) bool {
+	return c == '[' || c == ']' || isUnreserved(c)
+}
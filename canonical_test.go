@@ -0,0 +1,40 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalize(t *testing.T) {
+	testables := map[string]string{
+		"":                                     "",
+		"b=2&a=1":                              "a=1&b=2",
+		"q=hello+world&q2=hello%20world":       "q=hello%20world&q2=hello%20world",
+		"my+weird+field=q1%212%22%27w%245%267": "my%20weird%20field=q1%212%22%27w%245%267",
+		"f%5Bb%5D=1&f[a]=%7e":                  "f[a]=~&f[b]=1",
+		"foo&bar=":                             "bar=&foo",
+		"x[y][][z]=1&x[y][][w]=a&x[y][][z]=2":  "x[y][][w]=a&x[y][][z]=1&x[y][][z]=2",
+		"tags[]=b&tags[]=a":                    "tags[]=b&tags[]=a",
+	}
+
+	for input, expected := range testables {
+		canonical, err := Canonicalize(input)
+
+		if assert.NoError(t, err, input) {
+			assert.Equal(t, canonical, expected, input)
+
+			again, err := Canonicalize(canonical)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, again, canonical)
+			}
+		}
+	}
+
+	_, err := Canonicalize("x[y]=1&x[]=2")
+	assert.Error(t, err)
+
+	_, err = CanonicalizeParams(map[string]interface{}{"a": 1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,257 @@
+// Command qs decodes and encodes nested query strings with the same code Go
+// services use.
+//
+// Usage:
+//
+//	qs [-dialect rack|node|jquery|jquery-traditional] decode [query|url]
+//	qs [-dialect rack|node|jquery|jquery-traditional] encode < params.json
+//	qs [-dialect rack|node|jquery|jquery-traditional] diff query|url query|url
+//	qs [-dialect rack|node|jquery|jquery-traditional] normalize [query|url]
+//
+// Like diff(1), the diff subcommand exits with status 1 when the queries
+// differ. Invalid input and other errors exit with status 2.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/derekstavis/go-qs"
+)
+
+var dialects = map[string]qs.Dialect{
+	"rack":               qs.Rack,
+	"node":               qs.Node,
+	"jquery":             qs.JQuery,
+	"jquery-traditional": qs.JQueryDialect{Traditional: true},
+}
+
+// errDifferent makes run exit with status 1 without printing an error.
+var errDifferent = errors.New("queries differ")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("qs", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	name := flags.String("dialect", "rack", "query string `dialect`: rack, node, jquery or jquery-traditional")
+
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: qs [flags] decode [query|url]")
+		fmt.Fprintln(stderr, "       qs [flags] encode < params.json")
+		fmt.Fprintln(stderr, "       qs [flags] diff query|url query|url")
+		fmt.Fprintln(stderr, "       qs [flags] normalize [query|url]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	dialect, ok := dialects[*name]
+
+	if !ok {
+		fmt.Fprintf(stderr, "qs: unknown dialect %q\n", *name)
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var err error
+
+	switch flags.Arg(0) {
+	case "decode":
+		err = decode(dialect, flags.Args()[1:], stdin, stdout)
+	case "encode":
+		err = encode(dialect, stdin, stdout)
+	case "diff":
+		if flags.NArg() != 3 {
+			flags.Usage()
+			return 2
+		}
+
+		err = diff(dialect, flags.Arg(1), flags.Arg(2), stdout)
+	case "normalize":
+		err = normalize(dialect, flags.Args()[1:], stdin, stdout)
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	if err == errDifferent {
+		return 1
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "qs: %v\n", err)
+		return 2
+	}
+
+	return 0
+}
+
+// readQuery returns the query given as argument or on stdin. Full URLs with
+// a scheme and a host are reduced to their query string.
+func readQuery(args []string, stdin io.Reader) (string, error) {
+	query := ""
+
+	if len(args) > 0 {
+		query = args[0]
+	} else {
+		input, err := io.ReadAll(stdin)
+
+		if err != nil {
+			return "", err
+		}
+
+		query = string(input)
+	}
+
+	query = strings.TrimSpace(query)
+
+	if u, err := url.Parse(query); err == nil && u.Scheme != "" && u.Host != "" {
+		return u.RawQuery, nil
+	}
+
+	if i := strings.IndexByte(query, '?'); i != -1 && strings.HasPrefix(query, "/") {
+		query = query[i+1:]
+	}
+
+	return strings.TrimPrefix(query, "?"), nil
+}
+
+func decode(dialect qs.Dialect, args []string, stdin io.Reader, stdout io.Writer) error {
+	query, err := readQuery(args, stdin)
+
+	if err != nil {
+		return err
+	}
+
+	params, err := dialect.Unmarshal(query)
+
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(params)
+}
+
+func encode(dialect qs.Dialect, stdin io.Reader, stdout io.Writer) error {
+	decoder := json.NewDecoder(stdin)
+	decoder.UseNumber()
+
+	var params map[string]interface{}
+
+	if err := decoder.Decode(&params); err != nil {
+		return err
+	}
+
+	query, err := dialect.Marshal(stringify(params).(map[string]interface{}))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(stdout, query)
+	return err
+}
+
+func diff(dialect qs.Dialect, a, b string, stdout io.Writer) error {
+	trees := make([]map[string]interface{}, 2)
+
+	for i, arg := range []string{a, b} {
+		query, err := readQuery([]string{arg}, nil)
+
+		if err != nil {
+			return err
+		}
+
+		if trees[i], err = dialect.Unmarshal(query); err != nil {
+			return err
+		}
+	}
+
+	changes := qs.DiffParams(trees[0], trees[1])
+
+	for _, change := range changes {
+		before, _ := json.Marshal(change.Old)
+		after, _ := json.Marshal(change.New)
+
+		switch change.Kind {
+		case qs.Added:
+			fmt.Fprintf(stdout, "+ %s = %s\n", change.Path, after)
+		case qs.Removed:
+			fmt.Fprintf(stdout, "- %s = %s\n", change.Path, before)
+		case qs.Changed:
+			fmt.Fprintf(stdout, "~ %s = %s -> %s\n", change.Path, before, after)
+		}
+	}
+
+	if len(changes) > 0 {
+		return errDifferent
+	}
+
+	return nil
+}
+
+func normalize(dialect qs.Dialect, args []string, stdin io.Reader, stdout io.Writer) error {
+	query, err := readQuery(args, stdin)
+
+	if err != nil {
+		return err
+	}
+
+	params, err := dialect.Unmarshal(query)
+
+	if err != nil {
+		return err
+	}
+
+	canonical, err := qs.CanonicalizeParams(params)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(stdout, canonical)
+	return err
+}
+
+// stringify turns JSON numbers and booleans into the strings Unmarshal would
+// have produced for them.
+func stringify(value interface{}) interface{} {
+	switch vv := value.(type) {
+	case map[string]interface{}:
+		for k, v := range vv {
+			vv[k] = stringify(v)
+		}
+
+	case []interface{}:
+		for i, v := range vv {
+			vv[i] = stringify(v)
+		}
+
+	case json.Number:
+		return vv.String()
+
+	case bool:
+		return fmt.Sprint(vv)
+	}
+
+	return value
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func runCommand(stdin string, args ...string) (int, string, string) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	code := run(args, strings.NewReader(stdin), stdout, stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestDecode(t *testing.T) {
+	code, stdout, _ := runCommand("", "decode", "a[b][]=1&a[b][]=2&c")
+	assert.Equal(t, code, 0)
+	assert.Equal(t, stdout, "{\n  \"a\": {\n    \"b\": [\n      \"1\",\n      \"2\"\n    ]\n  },\n  \"c\": null\n}\n")
+
+	code, stdout, _ = runCommand("https://example.com/search?q=a+b&page=2#top\n", "decode")
+	assert.Equal(t, code, 0)
+	assert.Equal(t, stdout, "{\n  \"page\": \"2\",\n  \"q\": \"a b\"\n}\n")
+
+	code, stdout, _ = runCommand("", "decode", "/search?q=<b>")
+	assert.Equal(t, code, 0)
+	assert.Equal(t, stdout, "{\n  \"q\": \"<b>\"\n}\n")
+
+	code, stdout, _ = runCommand("", "-dialect", "node", "decode", "?a[0]=b&a[1]=c")
+	assert.Equal(t, code, 0)
+	assert.Equal(t, stdout, "{\n  \"a\": [\n    \"b\",\n    \"c\"\n  ]\n}\n")
+
+	code, stdout, _ = runCommand("", "decode", "utm:source=1&mailto:x=2")
+	assert.Equal(t, code, 0)
+	assert.Equal(t, stdout, "{\n  \"mailto:x\": \"2\",\n  \"utm:source\": \"1\"\n}\n")
+
+	code, _, stderr := runCommand("", "decode", "x[y]=1&x[]=2")
+	assert.Equal(t, code, 2)
+	assert.Contains(t, stderr, "Expected type")
+}
+
+func TestEncode(t *testing.T) {
+	code, stdout, _ := runCommand(`{"a": {"b": [1, true, "x y"]}}`, "encode")
+	assert.Equal(t, code, 0)
+	assert.Equal(t, stdout, "a[b][]=1&a[b][]=true&a[b][]=x+y\n")
+
+	code, stdout, _ = runCommand(`{"a": {"b": [1, 2]}, "c": null}`, "-dialect", "node", "encode")
+	assert.Equal(t, code, 0)
+	assert.Equal(t, stdout, "a%5Bb%5D%5B0%5D=1&a%5Bb%5D%5B1%5D=2&c=\n")
+
+	code, _, _ = runCommand(`[1, 2]`, "encode")
+	assert.Equal(t, code, 2)
+}
+
+func TestDiff(t *testing.T) {
+	code, stdout, _ := runCommand("", "diff", "a=hello%20world&b[]=1", "https://example.com/?b%5B%5D=1&a=hello+world")
+	assert.Equal(t, code, 0)
+	assert.Equal(t, stdout, "")
+
+	code, stdout, _ = runCommand("", "diff", "page=1&b[]=1&b[]=2&old", "page=2&b[]=1&c[d]=e")
+	assert.Equal(t, code, 1)
+	assert.Equal(t, stdout, "- b[1] = \"2\"\n+ c = {\"d\":\"e\"}\n- old = null\n~ page = \"1\" -> \"2\"\n")
+
+	code, _, _ = runCommand("", "diff", "a=b")
+	assert.Equal(t, code, 2)
+
+	code, _, stderr := runCommand("", "diff", "x[y]=1&x[]=2", "a=b")
+	assert.Equal(t, code, 2)
+	assert.Contains(t, stderr, "Expected type")
+}
+
+func TestNormalize(t *testing.T) {
+	code, stdout, _ := runCommand("", "normalize", "b=hello+world&a%5B%5D=1&a[]=2")
+	assert.Equal(t, code, 0)
+	assert.Equal(t, stdout, "a[]=1&a[]=2&b=hello%20world\n")
+
+	code, stdout, _ = runCommand("https://example.com/?z=1&a[1]=b&a[0]=a\n", "-dialect", "node", "normalize")
+	assert.Equal(t, code, 0)
+	assert.Equal(t, stdout, "a[]=a&a[]=b&z=1\n")
+}
+
+func TestUsage(t *testing.T) {
+	code, _, stderr := runCommand("")
+	assert.Equal(t, code, 2)
+	assert.Contains(t, stderr, "usage: qs")
+
+	code, _, _ = runCommand("", "explode")
+	assert.Equal(t, code, 2)
+
+	code, _, stderr = runCommand("", "-dialect", "php", "decode", "a=b")
+	assert.Equal(t, code, 2)
+	assert.Contains(t, stderr, "unknown dialect")
+}
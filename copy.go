@@ -0,0 +1,27 @@
+package qs
+
+// copyValue returns a deep copy of the hashes and arrays in value. Other
+// values are shared.
+func copyValue(value interface{}) interface{} {
+	switch vv := value.(type) {
+	case map[string]interface{}:
+		hash := make(map[string]interface{}, len(vv))
+
+		for k, v := range vv {
+			hash[k] = copyValue(v)
+		}
+
+		return hash
+
+	case []interface{}:
+		array := make([]interface{}, len(vv))
+
+		for i, v := range vv {
+			array[i] = copyValue(v)
+		}
+
+		return array
+	}
+
+	return value
+}
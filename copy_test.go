@@ -0,0 +1,20 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyValue(t *testing.T) {
+	value := map[string]interface{}{"a": []interface{}{map[string]interface{}{"b": "c"}}, "d": nil}
+	copied := copyValue(value).(map[string]interface{})
+
+	assert.Equal(t, copied, value)
+
+	copied["a"].([]interface{})[0].(map[string]interface{})["b"] = "x"
+	copied["d"] = "y"
+
+	assert.Equal(t, value, map[string]interface{}{"a": []interface{}{map[string]interface{}{"b": "c"}}, "d": nil})
+	assert.Equal(t, copyValue("leaf"), "leaf")
+}
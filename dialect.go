@@ -0,0 +1,21 @@
+package qs
+
+// A Dialect decodes and encodes query strings following the nesting
+// conventions of a specific framework or library.
+type Dialect interface {
+	Unmarshal(qs string) (map[string]interface{}, error)
+	Marshal(hash map[string]interface{}) (string, error)
+}
+
+// Rack is the dialect implemented by the package level Unmarshal and Marshal.
+var Rack Dialect = rackDialect{}
+
+type rackDialect struct{}
+
+func (rackDialect) Unmarshal(qs string) (map[string]interface{}, error) {
+	return Unmarshal(qs)
+}
+
+func (rackDialect) Marshal(hash map[string]interface{}) (string, error) {
+	return Marshal(hash)
+}
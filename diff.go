@@ -0,0 +1,131 @@
+package qs
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ChangeKind tells how a value differs between two params trees.
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota
+	Removed
+	Changed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	}
+
+	return "unknown"
+}
+
+// Change is a single difference at Path. Old is nil for additions and New
+// is nil for removals.
+type Change struct {
+	Kind ChangeKind
+	Path Path
+	Old  interface{}
+	New  interface{}
+}
+
+// Diff decodes two query strings with Unmarshal and returns their semantic
+// differences, regardless of key order or escaping.
+func Diff(a, b string) ([]Change, error) {
+	hashA, err := Unmarshal(a)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hashB, err := Unmarshal(b)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffParams(hashA, hashB), nil
+}
+
+// DiffParams returns the differences between two decoded trees. Hash keys
+// are compared in sorted order and array members by index, with trailing
+// members reported from the last one backwards when removed.
+func DiffParams(a, b map[string]interface{}) []Change {
+	changes := []Change{}
+	diffValues(Path{}, a, b, &changes)
+
+	return changes
+}
+
+func (p Path) child(element interface{}) Path {
+	return append(p[:len(p):len(p)], element)
+}
+
+func diffValues(path Path, a, b interface{}, changes *[]Change) {
+	hashA, okA := a.(map[string]interface{})
+	hashB, okB := b.(map[string]interface{})
+
+	if okA && okB {
+		keys := sortedKeys(hashA)
+
+		for _, k := range sortedKeys(hashB) {
+			if _, ok := hashA[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			va, inA := hashA[k]
+			vb, inB := hashB[k]
+
+			switch {
+			case !inB:
+				*changes = append(*changes, Change{Kind: Removed, Path: path.child(k), Old: va})
+			case !inA:
+				*changes = append(*changes, Change{Kind: Added, Path: path.child(k), New: vb})
+			default:
+				diffValues(path.child(k), va, vb, changes)
+			}
+		}
+
+		return
+	}
+
+	arrayA, okA := a.([]interface{})
+	arrayB, okB := b.([]interface{})
+
+	if okA && okB {
+		common := len(arrayA)
+
+		if len(arrayB) < common {
+			common = len(arrayB)
+		}
+
+		for i := 0; i < common; i++ {
+			diffValues(path.child(i), arrayA[i], arrayB[i], changes)
+		}
+
+		for i := len(arrayA) - 1; i >= common; i-- {
+			*changes = append(*changes, Change{Kind: Removed, Path: path.child(i), Old: arrayA[i]})
+		}
+
+		for i := common; i < len(arrayB); i++ {
+			*changes = append(*changes, Change{Kind: Added, Path: path.child(i), New: arrayB[i]})
+		}
+
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, Change{Kind: Changed, Path: path, Old: a, New: b})
+	}
+}
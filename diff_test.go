@@ -0,0 +1,44 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	changes, err := Diff("b=2&a=hello%20world&c[]=1", "a=hello+world&c%5B%5D=1&b=2")
+	if assert.NoError(t, err) {
+		assert.Empty(t, changes)
+	}
+
+	changes, err = Diff(
+		"page=1&filter[status]=open&filter[tags][]=a&filter[tags][]=b&filter[tags][]=c&old=1",
+		"page=2&filter[status]=open&filter[tags][]=a&filter[owner]=me&new=1",
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, changes, []Change{
+			Change{Kind: Added, Path: Path{"filter", "owner"}, New: "me"},
+			Change{Kind: Removed, Path: Path{"filter", "tags", 2}, Old: "c"},
+			Change{Kind: Removed, Path: Path{"filter", "tags", 1}, Old: "b"},
+			Change{Kind: Added, Path: Path{"new"}, New: "1"},
+			Change{Kind: Removed, Path: Path{"old"}, Old: "1"},
+			Change{Kind: Changed, Path: Path{"page"}, Old: "1", New: "2"},
+		})
+	}
+
+	changes, err = Diff("x[y][][z]=1&x[y][][z]=2", "x[y][][z]=1&x[y]=2")
+	if assert.NoError(t, err) {
+		assert.Equal(t, changes, []Change{
+			Change{Kind: Changed, Path: Path{"x", "y"}, Old: []interface{}{map[string]interface{}{"z": "1"}, map[string]interface{}{"z": "2"}}, New: "2"},
+		})
+		assert.Equal(t, changes[0].Path.String(), "x[y]")
+		assert.Equal(t, changes[0].Kind.String(), "changed")
+	}
+
+	_, err = Diff("x[y]=1&x[]=2", "")
+	assert.Error(t, err)
+
+	_, err = Diff("", "x[y]=1&x[]=2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package qs
+
+const upperhex = "0123456789ABCDEF"
+
+func isUnreserved(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	case c == '-', c == '.', c == '_', c == '~':
+		return true
+	}
+
+	return false
+}
+
+// escapeRFC3986 percent-encodes every byte of s outside the RFC 3986
+// unreserved set, which is what npm's qs and most signing schemes expect.
+func escapeRFC3986(s string) string {
+	return escapeFunc(s, isUnreserved)
+}
+
+// escapeURIComponent matches JavaScript's encodeURIComponent.
+func escapeURIComponent(s string) string {
+	return escapeFunc(s, func(c byte) bool {
+		switch c {
+		case '!', '\'', '(', ')', '*':
+			return true
+		}
+
+		return isUnreserved(c)
+	})
+}
+
+func escapeFunc(s string, keep func(byte) bool) string {
+	count := 0
+
+	for i := 0; i < len(s); i++ {
+		if !keep(s[i]) {
+			count++
+		}
+	}
+
+	if count == 0 {
+		return s
+	}
+
+	buf := make([]byte, 0, len(s)+2*count)
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if keep(c) {
+			buf = append(buf, c)
+		} else {
+			buf = append(buf, '%', upperhex[c>>4], upperhex[c&15])
+		}
+	}
+
+	return string(buf)
+}
@@ -0,0 +1,107 @@
+package qs
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// JQueryDialect matches the serialization performed by jQuery.param. In
+// Traditional mode arrays are written as repeated keys and nested hashes are
+// not supported, like jQuery.param(obj, true).
+type JQueryDialect struct {
+	Traditional bool
+}
+
+// JQuery is the JQueryDialect used by $.ajax with default settings.
+var JQuery = JQueryDialect{}
+
+// jqueryNode decodes both the indexed and the bracket notations emitted by
+// jQuery, as well as the repeated keys of traditional mode.
+var jqueryNode = NodeDialect{
+	ArrayLimit: math.MaxInt32,
+	Depth:      math.MaxInt32,
+}
+
+func (d JQueryDialect) Unmarshal(qs string) (map[string]interface{}, error) {
+	return jqueryNode.Unmarshal(qs)
+}
+
+func (d JQueryDialect) Marshal(hash map[string]interface{}) (string, error) {
+	components := []string{}
+
+	for _, k := range sortedKeys(hash) {
+		if err := d.buildParams(k, hash[k], &components); err != nil {
+			return "", err
+		}
+	}
+
+	return strings.Join(components, "&"), nil
+}
+
+func (d JQueryDialect) buildParams(prefix string, value interface{}, components *[]string) error {
+	switch vv := value.(type) {
+	case []interface{}:
+		for i, v := range vv {
+			if d.Traditional || strings.HasSuffix(prefix, "[]") {
+				if err := jqueryAdd(prefix, v, components); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			index := ""
+
+			if jqueryIsObject(v) {
+				index = strconv.Itoa(i)
+			}
+
+			if err := d.buildParams(prefix+"["+index+"]", v, components); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case map[string]interface{}:
+		if d.Traditional {
+			break
+		}
+
+		for _, k := range sortedKeys(vv) {
+			if err := d.buildParams(prefix+"["+k+"]", vv[k], components); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return jqueryAdd(prefix, value, components)
+}
+
+func jqueryIsObject(v interface{}) bool {
+	switch v.(type) {
+	case []interface{}, map[string]interface{}:
+		return true
+	}
+
+	return false
+}
+
+func jqueryAdd(key string, value interface{}, components *[]string) error {
+	if jqueryIsObject(value) {
+		return fmt.Errorf("Cannot serialize nested value '%T' for key '%s'", value, key)
+	}
+
+	str := ""
+
+	if value != nil {
+		str = fmt.Sprint(value)
+	}
+
+	*components = append(*components, escapeURIComponent(key)+"="+escapeURIComponent(str))
+	return nil
+}
@@ -0,0 +1,62 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJQueryMarshal(t *testing.T) {
+	querystring, err := JQuery.Marshal(map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{"1", "2"}}, "c": nil})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a%5Bb%5D%5B%5D=1&a%5Bb%5D%5B%5D=2&c=")
+	}
+
+	querystring, err = JQuery.Marshal(map[string]interface{}{"a": []interface{}{map[string]interface{}{"x": "1"}, map[string]interface{}{"x": "2 3"}}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a%5B0%5D%5Bx%5D=1&a%5B1%5D%5Bx%5D=2%203")
+	}
+
+	querystring, err = JQuery.Marshal(map[string]interface{}{"a": []interface{}{[]interface{}{"1"}, "2"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a%5B0%5D%5B%5D=1&a%5B%5D=2")
+	}
+
+	traditional := JQueryDialect{Traditional: true}
+
+	querystring, err = traditional.Marshal(map[string]interface{}{"a": []interface{}{"1", "2"}, "b": "(c)"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a=1&a=2&b=(c)")
+	}
+
+	_, err = traditional.Marshal(map[string]interface{}{"a": map[string]interface{}{"b": "c"}})
+	assert.Error(t, err)
+
+	_, err = traditional.Marshal(map[string]interface{}{"a": []interface{}{[]interface{}{"1"}}})
+	assert.Error(t, err)
+}
+
+func TestJQueryUnmarshal(t *testing.T) {
+	hash, err := JQuery.Unmarshal("a%5Bb%5D%5B%5D=1&a%5Bb%5D%5B%5D=2&c=")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{"1", "2"}}, "c": ""})
+	}
+
+	hash, err = JQuery.Unmarshal("a%5B0%5D%5Bx%5D=1&a%5B0%5D%5By%5D=2&a%5B1%5D%5Bx%5D=3")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": []interface{}{
+			map[string]interface{}{"x": "1", "y": "2"},
+			map[string]interface{}{"x": "3"},
+		}})
+	}
+
+	hash, err = JQuery.Unmarshal("a%5B30%5D%5Bx%5D=1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": []interface{}{map[string]interface{}{"x": "1"}}})
+	}
+
+	hash, err = JQueryDialect{Traditional: true}.Unmarshal("a=1&a=2&b=3")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": []interface{}{"1", "2"}, "b": "3"})
+	}
+}
@@ -0,0 +1,719 @@
+package qs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// JSONSchema validates decoded params against a JSON Schema document. The
+// core validation and applicator keywords of draft 2020-12 are supported,
+// along with local $ref pointers such as #/$defs/address. The unevaluated
+// and dynamic reference keywords are not.
+type JSONSchema struct {
+	root interface{}
+}
+
+// LoadJSONSchema reads a JSON Schema document from a local file.
+func LoadJSONSchema(path string) (*JSONSchema, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJSONSchema(data)
+}
+
+// ParseJSONSchema parses a JSON Schema document. It fails on keywords that
+// Validate does not support, rather than ignoring them.
+func ParseJSONSchema(data []byte) (*JSONSchema, error) {
+	var root interface{}
+
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	switch root.(type) {
+	case bool, map[string]interface{}:
+		if err := checkSchema(root, "#"); err != nil {
+			return nil, err
+		}
+
+		return &JSONSchema{root: root}, nil
+	}
+
+	return nil, fmt.Errorf("Expected a JSON Schema object or boolean, but got '%T'", root)
+}
+
+var unsupportedKeywords = map[string]bool{
+	"unevaluatedProperties": true,
+	"unevaluatedItems":      true,
+	"$dynamicRef":           true,
+	"$recursiveRef":         true,
+	"additionalItems":       true,
+	"dependencies":          true,
+}
+
+// checkSchema looks for unsupported keywords in schema and in the
+// subschemas it contains, with pointer locating schema in the document.
+func checkSchema(schema interface{}, pointer string) error {
+	hash, ok := schema.(map[string]interface{})
+
+	if !ok {
+		if _, ok := schema.(bool); ok {
+			return nil
+		}
+
+		return fmt.Errorf("Invalid schema of type '%T' at '%s'", schema, pointer)
+	}
+
+	for _, k := range sortedKeys(hash) {
+		location := pointer + "/" + escapePointer(k)
+
+		if unsupportedKeywords[k] {
+			return fmt.Errorf("Unsupported keyword '%s' at '%s'", k, pointer)
+		}
+
+		switch k {
+		case "additionalProperties", "items", "contains", "propertyNames", "not", "if", "then", "else":
+			if err := checkSchema(hash[k], location); err != nil {
+				return err
+			}
+
+		case "prefixItems", "allOf", "anyOf", "oneOf":
+			subschemas, ok := hash[k].([]interface{})
+
+			if !ok {
+				return fmt.Errorf("Expected an array for '%s', but got '%T'", location, hash[k])
+			}
+
+			for i, subschema := range subschemas {
+				if err := checkSchema(subschema, location+"/"+strconv.Itoa(i)); err != nil {
+					return err
+				}
+			}
+
+		case "$defs", "definitions", "properties", "patternProperties", "dependentSchemas":
+			subschemas, ok := hash[k].(map[string]interface{})
+
+			if !ok {
+				return fmt.Errorf("Expected an object for '%s', but got '%T'", location, hash[k])
+			}
+
+			for _, name := range sortedKeys(subschemas) {
+				if err := checkSchema(subschemas[name], location+"/"+escapePointer(name)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate checks params against the schema. Since query strings only carry
+// strings, string values are first coerced to int64, float64, bool or nil
+// where the schema, or the subschema matched by anyOf or oneOf, expects an
+// integer, number, boolean or null. It returns
+// the coerced tree along with ValidationErrors addressed by bracket paths.
+func (s *JSONSchema) Validate(params map[string]interface{}) (map[string]interface{}, error) {
+	validator := &schemaValidator{root: s.root, refs: map[string]bool{}}
+	coerced := validator.validate(s.root, params, Path{})
+
+	if validator.err != nil {
+		return nil, validator.err
+	}
+
+	hash, _ := coerced.(map[string]interface{})
+
+	if len(validator.violations) > 0 {
+		return hash, validator.violations
+	}
+
+	return hash, nil
+}
+
+type schemaValidator struct {
+	root       interface{}
+	violations ValidationErrors
+	err        error
+
+	// refs holds the $ref pointers being followed at each path, to detect
+	// cycles that never reach deeper into the value.
+	refs map[string]bool
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func (v *schemaValidator) fail(path Path, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// matches reports whether value is valid against schema, without recording
+// violations, and returns the value as coerced by schema.
+func (v *schemaValidator) matches(schema interface{}, value interface{}, path Path) (interface{}, bool) {
+	child := &schemaValidator{root: v.root, refs: v.refs}
+	coerced := child.validate(schema, value, path)
+
+	if child.err != nil && v.err == nil {
+		v.err = child.err
+	}
+
+	return coerced, len(child.violations) == 0
+}
+
+// resolve follows a JSON pointer fragment such as #/$defs/address. Plain
+// name fragments like #address are not supported.
+func (v *schemaValidator) resolve(ref string) (interface{}, bool) {
+	if ref != "#" && !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+
+	current := v.root
+
+	for _, token := range strings.Split(ref[1:], "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch node := current.(type) {
+		case map[string]interface{}:
+			child, ok := node[token]
+
+			if !ok {
+				return nil, false
+			}
+
+			current = child
+
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+
+			current = node[index]
+
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+func schemaTypes(schema map[string]interface{}) []string {
+	switch tt := schema["type"].(type) {
+	case string:
+		return []string{tt}
+	case []interface{}:
+		types := []string{}
+
+		for _, t := range tt {
+			if str, ok := t.(string); ok {
+				types = append(types, str)
+			}
+		}
+
+		return types
+	}
+
+	return nil
+}
+
+func coerce(value interface{}, types []string) interface{} {
+	str, ok := value.(string)
+
+	if !ok {
+		return value
+	}
+
+	for _, t := range types {
+		if t == "string" {
+			return value
+		}
+	}
+
+	for _, t := range types {
+		switch t {
+		case "integer":
+			if n, err := strconv.ParseInt(str, 10, 64); err == nil {
+				return n
+			}
+		case "number":
+			if n, err := strconv.ParseFloat(str, 64); err == nil {
+				return n
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(str); err == nil {
+				return b
+			}
+		case "null":
+			if str == "" {
+				return nil
+			}
+		}
+	}
+
+	return value
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch vv := value.(type) {
+	case int64:
+		return float64(vv), true
+	case float64:
+		return vv, true
+	}
+
+	return 0, false
+}
+
+func hasType(value interface{}, t string) bool {
+	switch t {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		n, ok := toNumber(value)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := toNumber(value)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	}
+
+	return false
+}
+
+func jsonEqual(a, b interface{}) bool {
+	na, okA := toNumber(a)
+	nb, okB := toNumber(b)
+
+	if okA && okB {
+		return na == nb
+	}
+
+	arrayA, okA := a.([]interface{})
+	arrayB, okB := b.([]interface{})
+
+	if okA && okB {
+		if len(arrayA) != len(arrayB) {
+			return false
+		}
+
+		for i := range arrayA {
+			if !jsonEqual(arrayA[i], arrayB[i]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	hashA, okA := a.(map[string]interface{})
+	hashB, okB := b.(map[string]interface{})
+
+	if okA && okB {
+		if len(hashA) != len(hashB) {
+			return false
+		}
+
+		for k, va := range hashA {
+			if vb, ok := hashB[k]; !ok || !jsonEqual(va, vb) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func (v *schemaValidator) validate(schema interface{}, value interface{}, path Path) interface{} {
+	switch ss := schema.(type) {
+	case bool:
+		if !ss {
+			v.fail(path, "is not allowed")
+		}
+
+		return value
+
+	case map[string]interface{}:
+		return v.validateObject(ss, value, path)
+	}
+
+	v.err = fmt.Errorf("Invalid schema of type '%T' at '%s'", schema, path)
+	return value
+}
+
+func (v *schemaValidator) validateObject(schema map[string]interface{}, value interface{}, path Path) interface{} {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, ok := v.resolve(ref)
+
+		if !ok {
+			v.err = fmt.Errorf("Unsupported $ref '%s'", ref)
+			return value
+		}
+
+		key := ref + " " + path.String()
+
+		if v.refs[key] {
+			v.err = fmt.Errorf("Circular $ref '%s' at '%s'", ref, path)
+			return value
+		}
+
+		v.refs[key] = true
+		value = v.validate(resolved, value, path)
+		delete(v.refs, key)
+	}
+
+	types := schemaTypes(schema)
+	value = coerce(value, types)
+
+	if len(types) > 0 {
+		valid := false
+
+		for _, t := range types {
+			if hasType(value, t) {
+				valid = true
+			}
+		}
+
+		if !valid {
+			v.fail(path, "must be of type %s", strings.Join(types, " or "))
+			return value
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		valid := false
+
+		for _, e := range enum {
+			if jsonEqual(value, e) {
+				valid = true
+			}
+		}
+
+		if !valid {
+			encoded, _ := json.Marshal(enum)
+			v.fail(path, "must be one of %s", encoded)
+		}
+	}
+
+	if constant, ok := schema["const"]; ok && !jsonEqual(value, constant) {
+		encoded, _ := json.Marshal(constant)
+		v.fail(path, "must be equal to %s", encoded)
+	}
+
+	if n, ok := toNumber(value); ok {
+		v.validateNumber(schema, n, path)
+	}
+
+	if str, ok := value.(string); ok {
+		v.validateString(schema, str, path)
+	}
+
+	switch vv := value.(type) {
+	case []interface{}:
+		value = v.validateArray(schema, vv, path)
+	case map[string]interface{}:
+		value = v.validateHash(schema, vv, path)
+	}
+
+	if subschemas, ok := schema["allOf"].([]interface{}); ok {
+		for _, subschema := range subschemas {
+			value = v.validate(subschema, value, path)
+		}
+	}
+
+	if subschemas, ok := schema["anyOf"].([]interface{}); ok {
+		matched := []interface{}{}
+
+		for _, subschema := range subschemas {
+			if coerced, ok := v.matches(subschema, value, path); ok {
+				matched = append(matched, coerced)
+			}
+		}
+
+		if len(matched) == 0 {
+			v.fail(path, "must match at least one schema in anyOf")
+		} else {
+			value = matched[0]
+		}
+	}
+
+	if subschemas, ok := schema["oneOf"].([]interface{}); ok {
+		matched := []interface{}{}
+
+		for _, subschema := range subschemas {
+			if coerced, ok := v.matches(subschema, value, path); ok {
+				matched = append(matched, coerced)
+			}
+		}
+
+		if len(matched) != 1 {
+			v.fail(path, "must match exactly one schema in oneOf")
+		} else {
+			value = matched[0]
+		}
+	}
+
+	if subschema, ok := schema["if"]; ok {
+		if coerced, ok := v.matches(subschema, value, path); ok {
+			if then, ok := schema["then"]; ok {
+				value = v.validate(then, coerced, path)
+			}
+		} else if otherwise, ok := schema["else"]; ok {
+			value = v.validate(otherwise, value, path)
+		}
+	}
+
+	if subschema, ok := schema["not"]; ok {
+		if _, ok := v.matches(subschema, value, path); ok {
+			v.fail(path, "must not match the schema in not")
+		}
+	}
+
+	return value
+}
+
+func (v *schemaValidator) validateNumber(schema map[string]interface{}, n float64, path Path) {
+	if min, ok := schema["minimum"].(float64); ok && n < min {
+		v.fail(path, "must be at least %v", min)
+	}
+
+	if max, ok := schema["maximum"].(float64); ok && n > max {
+		v.fail(path, "must be at most %v", max)
+	}
+
+	if min, ok := schema["exclusiveMinimum"].(float64); ok && n <= min {
+		v.fail(path, "must be greater than %v", min)
+	}
+
+	if max, ok := schema["exclusiveMaximum"].(float64); ok && n >= max {
+		v.fail(path, "must be less than %v", max)
+	}
+
+	if divisor, ok := schema["multipleOf"].(float64); ok && divisor > 0 {
+		if quotient := n / divisor; quotient != math.Trunc(quotient) {
+			v.fail(path, "must be a multiple of %v", divisor)
+		}
+	}
+}
+
+func (v *schemaValidator) validateString(schema map[string]interface{}, str string, path Path) {
+	length := float64(utf8.RuneCountInString(str))
+
+	if min, ok := schema["minLength"].(float64); ok && length < min {
+		v.fail(path, "must have a length of at least %v", min)
+	}
+
+	if max, ok := schema["maxLength"].(float64); ok && length > max {
+		v.fail(path, "must have a length of at most %v", max)
+	}
+
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+
+		if err != nil {
+			v.err = fmt.Errorf("Invalid pattern '%s' at '%s': %v", pattern, path, err)
+			return
+		}
+
+		if !re.MatchString(str) {
+			v.fail(path, "must match %s", pattern)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(schema map[string]interface{}, array []interface{}, path Path) []interface{} {
+	coerced := make([]interface{}, len(array))
+	prefixItems, _ := schema["prefixItems"].([]interface{})
+
+	for i, item := range array {
+		coerced[i] = item
+
+		if i < len(prefixItems) {
+			coerced[i] = v.validate(prefixItems[i], item, path.child(i))
+		} else if items, ok := schema["items"]; ok {
+			coerced[i] = v.validate(items, item, path.child(i))
+		}
+	}
+
+	length := float64(len(coerced))
+
+	if min, ok := schema["minItems"].(float64); ok && length < min {
+		v.fail(path, "must have at least %v items", min)
+	}
+
+	if max, ok := schema["maxItems"].(float64); ok && length > max {
+		v.fail(path, "must have at most %v items", max)
+	}
+
+	if contains, ok := schema["contains"]; ok {
+		count := 0.0
+
+		for i, item := range coerced {
+			if _, ok := v.matches(contains, item, path.child(i)); ok {
+				count++
+			}
+		}
+
+		min, ok := schema["minContains"].(float64)
+
+		if !ok {
+			min = 1
+		}
+
+		if count < min {
+			v.fail(path, "must contain at least %v matching items", min)
+		}
+
+		if max, ok := schema["maxContains"].(float64); ok && count > max {
+			v.fail(path, "must contain at most %v matching items", max)
+		}
+	}
+
+	if unique, ok := schema["uniqueItems"].(bool); ok && unique {
+		for i := range coerced {
+			for j := i + 1; j < len(coerced); j++ {
+				if jsonEqual(coerced[i], coerced[j]) {
+					v.fail(path, "must have unique items")
+					return coerced
+				}
+			}
+		}
+	}
+
+	return coerced
+}
+
+func (v *schemaValidator) validateHash(schema map[string]interface{}, hash map[string]interface{}, path Path) map[string]interface{} {
+	coerced := map[string]interface{}{}
+	properties, _ := schema["properties"].(map[string]interface{})
+	patternProperties, _ := schema["patternProperties"].(map[string]interface{})
+	additionalProperties, hasAdditional := schema["additionalProperties"]
+
+	patterns := map[string]*regexp.Regexp{}
+
+	for pattern := range patternProperties {
+		re, err := regexp.Compile(pattern)
+
+		if err != nil {
+			v.err = fmt.Errorf("Invalid pattern '%s' at '%s': %v", pattern, path, err)
+			return hash
+		}
+
+		patterns[pattern] = re
+	}
+
+	propertyNames, hasPropertyNames := schema["propertyNames"]
+
+	for _, k := range sortedKeys(hash) {
+		value := hash[k]
+		matched := false
+
+		if hasPropertyNames {
+			v.validate(propertyNames, k, path.child(k))
+		}
+
+		if subschema, ok := properties[k]; ok {
+			value = v.validate(subschema, value, path.child(k))
+			matched = true
+		}
+
+		for _, pattern := range sortedKeys(patternProperties) {
+			if patterns[pattern].MatchString(k) {
+				value = v.validate(patternProperties[pattern], value, path.child(k))
+				matched = true
+			}
+		}
+
+		if !matched && hasAdditional {
+			value = v.validate(additionalProperties, value, path.child(k))
+		}
+
+		coerced[k] = value
+	}
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		names := []string{}
+
+		for _, name := range required {
+			if str, ok := name.(string); ok {
+				names = append(names, str)
+			}
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			if _, ok := hash[name]; !ok {
+				v.fail(path.child(name), "is required")
+			}
+		}
+	}
+
+	if dependentRequired, ok := schema["dependentRequired"].(map[string]interface{}); ok {
+		for _, k := range sortedKeys(dependentRequired) {
+			names, _ := dependentRequired[k].([]interface{})
+
+			if _, ok := hash[k]; !ok {
+				continue
+			}
+
+			for _, name := range names {
+				if str, ok := name.(string); ok {
+					if _, ok := hash[str]; !ok {
+						v.fail(path.child(str), "is required when %s is present", k)
+					}
+				}
+			}
+		}
+	}
+
+	if dependentSchemas, ok := schema["dependentSchemas"].(map[string]interface{}); ok {
+		for _, k := range sortedKeys(dependentSchemas) {
+			if _, ok := hash[k]; !ok {
+				continue
+			}
+
+			if result, ok := v.validate(dependentSchemas[k], coerced, path).(map[string]interface{}); ok {
+				coerced = result
+			}
+		}
+	}
+
+	length := float64(len(hash))
+
+	if min, ok := schema["minProperties"].(float64); ok && length < min {
+		v.fail(path, "must have at least %v properties", min)
+	}
+
+	if max, ok := schema["maxProperties"].(float64); ok && length > max {
+		v.fail(path, "must have at most %v properties", max)
+	}
+
+	return coerced
+}
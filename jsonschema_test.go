@@ -0,0 +1,190 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema, err := LoadJSONSchema("testdata/search.schema.json")
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	hash, err := Unmarshal("q=shoes&page=2&ratio=0.5&exact=true&sort=name&debug&filter[status]=open&filter[owner][id]=7&ids[]=1&ids[]=2&range[]=1&range[]=5&owners[][id]=1&color=red&size=10&tag=public")
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	coerced, err := schema.Validate(hash)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, coerced, map[string]interface{}{
+			"q":      "shoes",
+			"page":   int64(2),
+			"ratio":  0.5,
+			"exact":  true,
+			"sort":   "name",
+			"debug":  nil,
+			"filter": map[string]interface{}{"status": "open", "owner": map[string]interface{}{"id": int64(7)}},
+			"ids":    []interface{}{int64(1), int64(2)},
+			"range":  []interface{}{int64(1), int64(5)},
+			"owners": []interface{}{map[string]interface{}{"id": int64(1)}},
+			"color":  "red",
+			"size":   int64(10),
+			"tag":    "public",
+		})
+	}
+
+	hash, err = Unmarshal("q=&page=0&ratio=1&exact=maybe&sort=price&filter[status]=pending&filter[owner][name]=x&ids[]=1&ids[]=1&ids[]=a&ids[]=3&range[]=1&range[]=2&range[]=3&owners[][id]=x&color=green&size=big&tag=internal&extra=1")
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = schema.Validate(hash)
+
+	if assert.IsType(t, err, ValidationErrors{}) {
+		messages := []string{}
+
+		for _, violation := range err.(ValidationErrors) {
+			messages = append(messages, violation.Path.String()+" "+violation.Message)
+		}
+
+		assert.Equal(t, messages, []string{
+			"color must match exactly one schema in oneOf",
+			"exact must be of type boolean",
+			"extra is not allowed",
+			"filter[owner][id] is required",
+			"filter[status] must match ^(open|closed)$",
+			"ids[2] must be of type integer",
+			"ids must have at most 3 items",
+			"ids must have unique items",
+			"owners[0][id] must be of type integer",
+			"page must be at least 1",
+			"q must have a length of at least 1",
+			"range[2] is not allowed",
+			"ratio must be less than 1",
+			"size must match at least one schema in anyOf",
+			`sort must be one of ["name","date"]`,
+			"tag must not match the schema in not",
+		})
+	}
+
+	_, err = schema.Validate(map[string]interface{}{})
+
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "filter is required; q is required")
+	}
+}
+
+func TestParseJSONSchema(t *testing.T) {
+	_, err := ParseJSONSchema([]byte(`[]`))
+	assert.Error(t, err)
+
+	_, err = ParseJSONSchema([]byte(`{`))
+	assert.Error(t, err)
+
+	_, err = LoadJSONSchema("testdata/missing.schema.json")
+	assert.Error(t, err)
+
+	schema, err := ParseJSONSchema([]byte(`{"properties": {"a": {"$ref": "other.json"}}}`))
+
+	if assert.NoError(t, err) {
+		_, err = schema.Validate(map[string]interface{}{"a": "b"})
+		assert.EqualError(t, err, "Unsupported $ref 'other.json'")
+	}
+
+	schema, err = ParseJSONSchema([]byte(`false`))
+
+	if assert.NoError(t, err) {
+		_, err = schema.Validate(map[string]interface{}{})
+		assert.Error(t, err)
+	}
+}
+
+func TestJSONSchemaApplicators(t *testing.T) {
+	schema, err := ParseJSONSchema([]byte(`{
+		"type": "object",
+		"propertyNames": {"pattern": "^[a-z_]+$"},
+		"properties": {
+			"kind": {"enum": ["user", "team"]},
+			"tags": {"type": "array", "contains": {"const": "main"}, "maxContains": 1}
+		},
+		"if": {"properties": {"kind": {"const": "user"}}},
+		"then": {"required": ["email"]},
+		"else": {"required": ["members"]},
+		"dependentRequired": {"lat": ["lng"]},
+		"dependentSchemas": {"page": {"properties": {"page": {"type": "integer"}}}}
+	}`))
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	hash, err := schema.Validate(map[string]interface{}{"kind": "user", "email": "a@b.c", "page": "2", "tags": []interface{}{"main", "x"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash["page"], int64(2))
+	}
+
+	_, err = schema.Validate(map[string]interface{}{"kind": "user", "Bad": "1", "lat": "1", "tags": []interface{}{"x", "main", "main"}})
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "Bad must match ^[a-z_]+$; tags must contain at most 1 matching items; lng is required when lat is present; email is required")
+	}
+
+	_, err = schema.Validate(map[string]interface{}{"kind": "team", "tags": []interface{}{}, "page": "x"})
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "tags must contain at least 1 matching items; page must be of type integer; members is required")
+	}
+}
+
+func TestParseJSONSchemaUnsupported(t *testing.T) {
+	_, err := ParseJSONSchema([]byte(`{"properties": {"a": {"unevaluatedProperties": false}}}`))
+	assert.EqualError(t, err, "Unsupported keyword 'unevaluatedProperties' at '#/properties/a'")
+
+	_, err = ParseJSONSchema([]byte(`{"$defs": {"a/b": {"items": {"$dynamicRef": "#x"}}}}`))
+	assert.EqualError(t, err, "Unsupported keyword '$dynamicRef' at '#/$defs/a~1b/items'")
+
+	_, err = ParseJSONSchema([]byte(`{"anyOf": {}}`))
+	assert.Error(t, err)
+
+	_, err = ParseJSONSchema([]byte(`{"properties": {"unevaluatedItems": {"type": "string"}}}`))
+	assert.NoError(t, err)
+}
+
+func TestJSONSchemaRefs(t *testing.T) {
+	schema, err := ParseJSONSchema([]byte(`{"properties": {"a": {"$ref": "#a"}}, "$defs": {"a": {"$anchor": "a"}}}`))
+
+	if assert.NoError(t, err) {
+		_, err = schema.Validate(map[string]interface{}{"a": "b"})
+		assert.EqualError(t, err, "Unsupported $ref '#a'")
+	}
+
+	schema, err = ParseJSONSchema([]byte(`{"$ref": "#"}`))
+
+	if assert.NoError(t, err) {
+		_, err = schema.Validate(map[string]interface{}{})
+		assert.EqualError(t, err, "Circular $ref '#' at ''")
+	}
+
+	schema, err = ParseJSONSchema([]byte(`{"anyOf": [{"$ref": "#/$defs/a"}], "$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}}`))
+
+	if assert.NoError(t, err) {
+		_, err = schema.Validate(map[string]interface{}{})
+		assert.EqualError(t, err, "Circular $ref '#/$defs/a' at ''")
+	}
+
+	schema, err = ParseJSONSchema([]byte(`{"type": "object", "properties": {"q": {"type": "integer"}, "child": {"$ref": "#"}}}`))
+
+	if assert.NoError(t, err) {
+		hash, err := schema.Validate(map[string]interface{}{"q": "1", "child": map[string]interface{}{"q": "2", "child": map[string]interface{}{"q": "x"}}})
+
+		if assert.Error(t, err) {
+			assert.Equal(t, err.Error(), "child[child][q] must be of type integer")
+			assert.Equal(t, hash["child"].(map[string]interface{})["q"], int64(2))
+		}
+	}
+}
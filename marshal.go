@@ -3,6 +3,7 @@ package qs
 import (
 	"fmt"
 	"net/url"
+	"strings"
 )
 
 func Marshal(hash map[string]interface{}) (string, error) {
@@ -10,60 +11,107 @@ func Marshal(hash map[string]interface{}) (string, error) {
 }
 
 func buildNestedQuery(value interface{}, prefix string) (string, error) {
-	components := ""
+	components, err := buildNestedComponents(value, prefix, url.QueryEscape)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(components, "&"), nil
+}
+
+func buildNestedComponents(value interface{}, prefix string, escape func(string) string) ([]string, error) {
+	components := []string{}
 
 	switch vv := value.(type) {
 	case []interface{}:
-		for i, v := range vv {
-			component, err := buildNestedQuery(v, prefix+"[]")
+		for _, v := range vv {
+			component, err := buildNestedComponents(v, prefix+"[]", escape)
 
 			if err != nil {
-				return "", err
+				return nil, err
 			}
 
-			components += component
-
-			if i < len(vv)-1 {
-				components += "&"
-			}
+			components = append(components, component...)
 		}
 
 	case map[string]interface{}:
-		length := len(vv)
-
-		for k, v := range vv {
-			childPrefix := ""
+		for _, k := range sortedKeys(vv) {
+			component, err := buildNestedComponents(vv[k], childQueryPrefix(prefix, k, escape), escape)
 
-			if prefix != "" {
-				childPrefix = prefix + "[" + url.QueryEscape(k) + "]"
-			} else {
-				childPrefix = url.QueryEscape(k)
+			if err != nil {
+				return nil, err
 			}
 
-			component, err := buildNestedQuery(v, childPrefix)
+			components = append(components, component...)
+		}
+
+	case *OrderedMap:
+		for _, k := range vv.Keys() {
+			v, _ := vv.Get(k)
+			component, err := buildNestedComponents(v, childQueryPrefix(prefix, k, escape), escape)
 
 			if err != nil {
-				return "", err
+				return nil, err
 			}
 
-			components += component
-			length -= 1
-
-			if length > 0 {
-				components += "&"
-			}
+			components = append(components, component...)
 		}
 
 	case string:
 		if prefix == "" {
-			return "", fmt.Errorf("value must be a map[string]interface{}")
+			return nil, fmt.Errorf("value must be a map[string]interface{}")
 		}
 
-		components += prefix + "=" + url.QueryEscape(vv)
+		components = append(components, prefix+"="+escape(vv))
 
 	default:
-		components += prefix
+		components = append(components, prefix)
 	}
 
 	return components, nil
 }
+
+func childQueryPrefix(prefix string, key string, escape func(string) string) string {
+	if prefix == "" {
+		return escape(key)
+	}
+
+	return prefix + "[" + escape(key) + "]"
+}
+
+// flattenParams calls fn with the unescaped bracket key of every leaf below
+// value, visiting hash keys in sorted order.
+func flattenParams(value interface{}, prefix string, fn func(key string, value interface{}) error) error {
+	switch vv := value.(type) {
+	case []interface{}:
+		for _, v := range vv {
+			if err := flattenParams(v, prefix+"[]", fn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case map[string]interface{}:
+		for _, k := range sortedKeys(vv) {
+			childPrefix := k
+
+			if prefix != "" {
+				childPrefix = prefix + "[" + k + "]"
+			}
+
+			if err := flattenParams(vv[k], childPrefix, fn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if prefix == "" {
+		return fmt.Errorf("value must be a map[string]interface{}")
+	}
+
+	return fn(prefix, value)
+}
@@ -0,0 +1,91 @@
+package qs
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Segment is a path segment together with its matrix parameters, as in
+// /cars;color=red;year=2012.
+type Segment struct {
+	Name   string
+	Params map[string]interface{}
+}
+
+// UnmarshalMatrix decodes a single path segment. Matrix parameter names are
+// nested with the same bracket rules as Unmarshal.
+func UnmarshalMatrix(segment string) (Segment, error) {
+	components := strings.Split(segment, ";")
+	result := Segment{Name: pathUnescape(components[0]), Params: map[string]interface{}{}}
+
+	for _, c := range components[1:] {
+		tuple := strings.SplitN(c, "=", 2)
+		key := pathUnescape(tuple[0])
+		value := interface{}(nil)
+
+		if len(tuple) > 1 {
+			value = pathUnescape(tuple[1])
+		}
+
+		if err := normalizeParams(result.Params, key, value); err != nil {
+			return Segment{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// MarshalMatrix encodes a path segment and its matrix parameters, writing
+// keys in sorted order so the same segment always yields the same path.
+func MarshalMatrix(segment Segment) (string, error) {
+	components, err := buildNestedComponents(segment.Params, "", escapeRFC3986)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(append([]string{url.PathEscape(segment.Name)}, components...), ";"), nil
+}
+
+// UnmarshalPath decodes every segment of a slash separated path.
+func UnmarshalPath(path string) ([]Segment, error) {
+	parts := strings.Split(path, "/")
+	segments := make([]Segment, len(parts))
+
+	for i, part := range parts {
+		segment, err := UnmarshalMatrix(part)
+
+		if err != nil {
+			return nil, err
+		}
+
+		segments[i] = segment
+	}
+
+	return segments, nil
+}
+
+// MarshalPath joins the encoded segments with slashes.
+func MarshalPath(segments []Segment) (string, error) {
+	parts := make([]string, len(segments))
+
+	for i, segment := range segments {
+		part, err := MarshalMatrix(segment)
+
+		if err != nil {
+			return "", err
+		}
+
+		parts[i] = part
+	}
+
+	return strings.Join(parts, "/"), nil
+}
+
+func pathUnescape(s string) string {
+	if unesc, err := url.PathUnescape(s); err == nil {
+		return unesc
+	}
+
+	return s
+}
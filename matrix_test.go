@@ -0,0 +1,79 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalMatrix(t *testing.T) {
+	segment, err := UnmarshalMatrix("cars")
+	if assert.NoError(t, err) {
+		assert.Equal(t, segment, Segment{Name: "cars", Params: map[string]interface{}{}})
+	}
+
+	segment, err = UnmarshalMatrix("cars;color=red;year=2012;used")
+	if assert.NoError(t, err) {
+		assert.Equal(t, segment, Segment{Name: "cars", Params: map[string]interface{}{"color": "red", "year": "2012", "used": nil}})
+	}
+
+	segment, err = UnmarshalMatrix("my%20cars;filter[color][]=red;filter[color][]=dark+blue;q=a%3Bb")
+	if assert.NoError(t, err) {
+		assert.Equal(t, segment, Segment{Name: "my cars", Params: map[string]interface{}{
+			"filter": map[string]interface{}{"color": []interface{}{"red", "dark+blue"}},
+			"q":      "a;b",
+		}})
+	}
+
+	_, err = UnmarshalMatrix("cars;x[y]=1;x[]=2")
+	assert.Error(t, err)
+}
+
+func TestUnmarshalPath(t *testing.T) {
+	segments, err := UnmarshalPath("/cars;color=red;year=2012/parts")
+	if assert.NoError(t, err) {
+		assert.Equal(t, segments, []Segment{
+			Segment{Name: "", Params: map[string]interface{}{}},
+			Segment{Name: "cars", Params: map[string]interface{}{"color": "red", "year": "2012"}},
+			Segment{Name: "parts", Params: map[string]interface{}{}},
+		})
+	}
+}
+
+func TestMarshalPath(t *testing.T) {
+	path, err := MarshalPath([]Segment{
+		Segment{Name: ""},
+		Segment{Name: "cars", Params: map[string]interface{}{"color": "dark blue"}},
+		Segment{Name: "parts"},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, path, "/cars;color=dark%20blue/parts")
+	}
+
+	for i := 0; i < 10; i++ {
+		path, err = MarshalPath([]Segment{
+			Segment{Name: "cars", Params: map[string]interface{}{"year": "2012", "color": "red", "engine": map[string]interface{}{"type": "v8", "hp": "400"}}},
+			Segment{Name: "parts", Params: map[string]interface{}{"b": "2", "a": "1"}},
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, path, "cars;color=red;engine[hp]=400;engine[type]=v8;year=2012/parts;a=1;b=2")
+		}
+	}
+
+	testables := []Segment{
+		Segment{Name: "a b", Params: map[string]interface{}{"q": "a;b=c/d+e"}},
+		Segment{Name: "cars", Params: map[string]interface{}{"x": map[string]interface{}{"y": []interface{}{map[string]interface{}{"z": "1"}}}}},
+	}
+
+	for _, v := range testables {
+		segment, err := MarshalMatrix(v)
+
+		if assert.NoError(t, err) {
+			decoded, err := UnmarshalMatrix(segment)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, decoded, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package qs
+
+import "fmt"
+
+// ArrayPolicy decides how Merge combines two arrays at the same key.
+type ArrayPolicy int
+
+const (
+	// ReplaceArrays keeps the array from src.
+	ReplaceArrays ArrayPolicy = iota
+	// ConcatArrays appends the members from src to the ones in dst.
+	ConcatArrays
+)
+
+// ConflictPolicy decides what Merge does when a key holds a hash, an array
+// or a scalar in dst and a different one of those in src. Nil counts as a
+// scalar in dst and is never a conflict in src.
+type ConflictPolicy int
+
+const (
+	// ConflictError makes Merge fail without modifying dst.
+	ConflictError ConflictPolicy = iota
+	// ConflictOverride keeps the value from src.
+	ConflictOverride
+	// ConflictKeep keeps the value from dst.
+	ConflictKeep
+)
+
+// MergeOptions configures Merge. The zero value replaces arrays and fails
+// on type conflicts.
+type MergeOptions struct {
+	Arrays    ArrayPolicy
+	Conflicts ConflictPolicy
+	// NilDeletes removes keys whose src value is nil instead of storing nil.
+	NilDeletes bool
+}
+
+// Merge deep merges src into dst. Hashes are merged recursively and
+// scalars from src override the ones in dst. Hashes and arrays from src are
+// copied, so later changes to dst do not affect src.
+func Merge(dst, src map[string]interface{}, opts MergeOptions) error {
+	if opts.Conflicts == ConflictError {
+		if err := findConflict(Path{}, dst, src); err != nil {
+			return err
+		}
+	}
+
+	mergeHash(dst, src, opts)
+	return nil
+}
+
+type valueKind int
+
+const (
+	scalarValue valueKind = iota
+	hashValue
+	arrayValue
+)
+
+func kindOf(value interface{}) valueKind {
+	switch value.(type) {
+	case map[string]interface{}:
+		return hashValue
+	case []interface{}:
+		return arrayValue
+	}
+
+	return scalarValue
+}
+
+// conflicts tells whether merging b over a at the same key is a type
+// conflict. A nil b replaces anything, but a nil a conflicts with a hash or
+// an array, as normalizeParams reports for a&a[b]=1.
+func conflicts(a, b interface{}) bool {
+	return b != nil && kindOf(a) != kindOf(b)
+}
+
+func findConflict(path Path, dst, src map[string]interface{}) error {
+	for _, k := range sortedKeys(src) {
+		existing, ok := dst[k]
+
+		if !ok {
+			continue
+		}
+
+		if conflicts(existing, src[k]) {
+			expected := fmt.Sprintf("%T", src[k])
+
+			switch kindOf(src[k]) {
+			case hashValue:
+				expected = "map[string]interface{}"
+			case arrayValue:
+				expected = "[]interface{}"
+			}
+
+			return fmt.Errorf("Expected type '%s' for key '%s', but got '%T'", expected, path.child(k), existing)
+		}
+
+		hashA, okA := existing.(map[string]interface{})
+		hashB, okB := src[k].(map[string]interface{})
+
+		if okA && okB {
+			if err := findConflict(path.child(k), hashA, hashB); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func mergeHash(dst, src map[string]interface{}, opts MergeOptions) {
+	for k, v := range src {
+		existing, ok := dst[k]
+
+		if v == nil && opts.NilDeletes {
+			delete(dst, k)
+			continue
+		}
+
+		if ok && conflicts(existing, v) && opts.Conflicts == ConflictKeep {
+			continue
+		}
+
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			child, ok := existing.(map[string]interface{})
+
+			if !ok {
+				child = map[string]interface{}{}
+				dst[k] = child
+			}
+
+			mergeHash(child, vv, opts)
+
+		case []interface{}:
+			array, ok := existing.([]interface{})
+
+			if ok && opts.Arrays == ConcatArrays {
+				dst[k] = append(array[:len(array):len(array)], copyValue(vv).([]interface{})...)
+			} else {
+				dst[k] = copyValue(vv)
+			}
+
+		default:
+			dst[k] = v
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	defaults := map[string]interface{}{
+		"page":   "1",
+		"tags":   []interface{}{"a"},
+		"filter": map[string]interface{}{"status": "open", "owner": "me"},
+	}
+
+	prefs := map[string]interface{}{
+		"tags":   []interface{}{"b"},
+		"filter": map[string]interface{}{"owner": "team", "label": map[string]interface{}{"name": "bug"}},
+		"sort":   nil,
+	}
+
+	err := Merge(defaults, prefs, MergeOptions{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, defaults, map[string]interface{}{
+			"page":   "1",
+			"tags":   []interface{}{"b"},
+			"filter": map[string]interface{}{"status": "open", "owner": "team", "label": map[string]interface{}{"name": "bug"}},
+			"sort":   nil,
+		})
+	}
+
+	defaults["filter"].(map[string]interface{})["label"].(map[string]interface{})["name"] = "feature"
+	assert.Equal(t, prefs["filter"].(map[string]interface{})["label"], map[string]interface{}{"name": "bug"})
+
+	tags := []interface{}{"a"}
+	dst := map[string]interface{}{"tags": tags, "sort": "name"}
+
+	err = Merge(dst, map[string]interface{}{"tags": []interface{}{"b", "c"}, "sort": nil}, MergeOptions{Arrays: ConcatArrays, NilDeletes: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, dst, map[string]interface{}{"tags": []interface{}{"a", "b", "c"}})
+		assert.Equal(t, tags, []interface{}{"a"})
+	}
+
+	src := map[string]interface{}{"l": []interface{}{map[string]interface{}{"x": "1"}}}
+
+	for _, arrays := range []ArrayPolicy{ReplaceArrays, ConcatArrays} {
+		dst = map[string]interface{}{"l": []interface{}{}}
+
+		err = Merge(dst, src, MergeOptions{Arrays: arrays})
+		if assert.NoError(t, err) {
+			dst["l"].([]interface{})[0].(map[string]interface{})["x"] = "2"
+			assert.Equal(t, src, map[string]interface{}{"l": []interface{}{map[string]interface{}{"x": "1"}}})
+		}
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	dst := map[string]interface{}{"a": "1", "x": map[string]interface{}{"y": []interface{}{"1"}}}
+
+	err := Merge(dst, map[string]interface{}{"a": "2", "x": map[string]interface{}{"y": map[string]interface{}{"z": "1"}}}, MergeOptions{})
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "Expected type 'map[string]interface{}' for key 'x[y]', but got '[]interface {}'")
+	}
+
+	assert.Equal(t, dst, map[string]interface{}{"a": "1", "x": map[string]interface{}{"y": []interface{}{"1"}}})
+
+	err = Merge(dst, map[string]interface{}{"a": map[string]interface{}{"b": "c"}}, MergeOptions{})
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "Expected type 'map[string]interface{}' for key 'a', but got 'string'")
+	}
+
+	err = Merge(dst, map[string]interface{}{"a": map[string]interface{}{"b": "c"}, "x": "flat"}, MergeOptions{Conflicts: ConflictKeep})
+	if assert.NoError(t, err) {
+		assert.Equal(t, dst, map[string]interface{}{"a": "1", "x": map[string]interface{}{"y": []interface{}{"1"}}})
+	}
+
+	err = Merge(dst, map[string]interface{}{"a": map[string]interface{}{"b": "c", "d": nil}, "x": "flat"}, MergeOptions{Conflicts: ConflictOverride, NilDeletes: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, dst, map[string]interface{}{"a": map[string]interface{}{"b": "c"}, "x": "flat"})
+	}
+
+	dst = map[string]interface{}{"a": nil, "b": map[string]interface{}{"c": "d"}}
+
+	err = Merge(dst, map[string]interface{}{"a": []interface{}{"1"}}, MergeOptions{})
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "Expected type '[]interface{}' for key 'a', but got '<nil>'")
+	}
+
+	err = Merge(dst, map[string]interface{}{"a": "1", "b": nil}, MergeOptions{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, dst, map[string]interface{}{"a": "1", "b": nil})
+	}
+}
@@ -0,0 +1,58 @@
+package qs
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextKey struct{}
+
+// ErrorHandler writes the response for a request whose params could not be
+// decoded.
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+func badRequest(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+}
+
+// NewContext returns a copy of ctx carrying params.
+func NewContext(ctx context.Context, params map[string]interface{}) context.Context {
+	return context.WithValue(ctx, contextKey{}, params)
+}
+
+// FromContext returns the params stored in ctx by Middleware.
+func FromContext(ctx context.Context) (map[string]interface{}, bool) {
+	params, ok := ctx.Value(contextKey{}).(map[string]interface{})
+	return params, ok
+}
+
+// Middleware decodes each request once with decoder and stores the params
+// in the request context, where handlers read them with FromContext.
+// Requests that already carry params are passed through untouched. Decoding
+// errors are passed to onError, which defaults to a 400 response. Temporary
+// files of multipart uploads are removed once the handler returns.
+func Middleware(decoder RequestDecoder, onError ErrorHandler) func(http.Handler) http.Handler {
+	if onError == nil {
+		onError = badRequest
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := FromContext(r.Context()); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			params, err := decoder.Decode(r)
+
+			if err != nil {
+				onError(w, r, err)
+				return
+			}
+
+			defer RemoveFiles(params)
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), params)))
+		})
+	}
+}
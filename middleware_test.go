@@ -0,0 +1,64 @@
+package qs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	calls := 0
+	params := map[string]interface{}(nil)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		params, _ = FromContext(r.Context())
+	})
+
+	middleware := Middleware(RequestDecoder{}, nil)
+	server := middleware(middleware(handler))
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/?user[name]=foo", nil))
+
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, calls, 1)
+	assert.Equal(t, params, map[string]interface{}{"user": map[string]interface{}{"name": "foo"}})
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/?x[y]=1&x[]=1", nil))
+
+	assert.Equal(t, w.Code, http.StatusBadRequest)
+	assert.Equal(t, calls, 1)
+}
+
+func TestMiddlewareErrorHandler(t *testing.T) {
+	handled := error(nil)
+
+	onError := func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+
+	server := Middleware(RequestDecoder{}, onError)(http.NotFoundHandler())
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/?x[y]=1&x[]=1", nil))
+
+	assert.Equal(t, w.Code, http.StatusUnprocessableEntity)
+	assert.Error(t, handled)
+}
+
+func TestFromContext(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+
+	_, ok := FromContext(r.Context())
+	assert.False(t, ok)
+
+	params, ok := FromContext(NewContext(r.Context(), map[string]interface{}{"a": "b"}))
+	if assert.True(t, ok) {
+		assert.Equal(t, params, map[string]interface{}{"a": "b"})
+	}
+}
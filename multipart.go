@@ -0,0 +1,301 @@
+package qs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File is a file part decoded by UnmarshalMultipart. Its content is kept in
+// memory or, above the memory threshold, in a temporary file that is
+// deleted by Remove.
+type File struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	Header      textproto.MIMEHeader
+
+	content []byte
+	tmpfile string
+}
+
+type sectionReadCloser struct {
+	*io.SectionReader
+}
+
+func (sectionReadCloser) Close() error {
+	return nil
+}
+
+// Open returns a reader for the file content.
+func (f *File) Open() (multipart.File, error) {
+	if f.tmpfile != "" {
+		return os.Open(f.tmpfile)
+	}
+
+	return sectionReadCloser{io.NewSectionReader(bytes.NewReader(f.content), 0, int64(len(f.content)))}, nil
+}
+
+// Remove deletes the temporary file backing f, if any.
+func (f *File) Remove() error {
+	if f.tmpfile == "" {
+		return nil
+	}
+
+	err := os.Remove(f.tmpfile)
+	f.tmpfile = ""
+
+	return err
+}
+
+// RemoveFiles removes the temporary files of every File in params.
+func RemoveFiles(params map[string]interface{}) error {
+	return removeFiles(params)
+}
+
+func removeFiles(value interface{}) error {
+	var err error
+
+	switch vv := value.(type) {
+	case *File:
+		return vv.Remove()
+
+	case []interface{}:
+		for _, v := range vv {
+			if e := removeFiles(v); e != nil {
+				err = e
+			}
+		}
+
+	case map[string]interface{}:
+		for _, v := range vv {
+			if e := removeFiles(v); e != nil {
+				err = e
+			}
+		}
+	}
+
+	return err
+}
+
+// UnmarshalMultipart decodes a multipart/form-data body, nesting part names
+// with the same rules as Unmarshal. Text parts become strings and file parts
+// become *File values. Up to maxMemory bytes of files are kept in memory,
+// while text parts may use a further 10 MB. Temporary files of parts that
+// are replaced by a later part with the same name are removed.
+func UnmarshalMultipart(r io.Reader, boundary string, maxMemory int64) (map[string]interface{}, error) {
+	reader := multipart.NewReader(r, boundary)
+	params := map[string]interface{}{}
+	maxValueBytes := maxMemory + int64(10<<20)
+	files := []*File{}
+
+	for {
+		part, err := reader.NextPart()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			removeFileList(files)
+			return nil, err
+		}
+
+		name := part.FormName()
+
+		if name == "" {
+			continue
+		}
+
+		value := interface{}(nil)
+
+		if part.FileName() == "" {
+			value, err = readValuePart(part, &maxValueBytes)
+		} else {
+			var file *File
+
+			file, err = readFilePart(part, &maxMemory)
+
+			if err == nil {
+				files = append(files, file)
+				value = file
+			}
+		}
+
+		if err != nil {
+			removeFileList(files)
+			return nil, err
+		}
+
+		if err := normalizeParams(params, name, value); err != nil {
+			removeFileList(files)
+			return nil, err
+		}
+	}
+
+	reachable := map[*File]bool{}
+
+	Walk(params, func(path Path, value interface{}) error {
+		if file, ok := value.(*File); ok {
+			reachable[file] = true
+		}
+
+		return nil
+	})
+
+	for _, file := range files {
+		if !reachable[file] {
+			file.Remove()
+		}
+	}
+
+	return params, nil
+}
+
+func removeFileList(files []*File) {
+	for _, file := range files {
+		file.Remove()
+	}
+}
+
+func readValuePart(part *multipart.Part, remaining *int64) (string, error) {
+	var b bytes.Buffer
+
+	n, err := io.CopyN(&b, part, *remaining+1)
+
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	*remaining -= n
+
+	if *remaining < 0 {
+		return "", multipart.ErrMessageTooLarge
+	}
+
+	return b.String(), nil
+}
+
+func readFilePart(part *multipart.Part, remaining *int64) (*File, error) {
+	var b bytes.Buffer
+
+	file := &File{
+		Filename:    part.FileName(),
+		ContentType: part.Header.Get("Content-Type"),
+		Header:      part.Header,
+	}
+
+	n, err := io.CopyN(&b, part, *remaining+1)
+
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if n <= *remaining {
+		*remaining -= n
+		file.content = b.Bytes()
+		file.Size = n
+
+		return file, nil
+	}
+
+	tmp, err := os.CreateTemp("", "qs-multipart-")
+
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := io.Copy(tmp, io.MultiReader(&b, part))
+
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	file.tmpfile = tmp.Name()
+	file.Size = size
+
+	return file, nil
+}
+
+// MarshalMultipart encodes hash as a multipart/form-data body, naming parts
+// with the bracket notation used by Marshal. *File values and readers with a
+// Name method, such as *os.File, are written as file parts. It returns the
+// body and its Content-Type, including the boundary.
+func MarshalMultipart(hash map[string]interface{}) (*bytes.Buffer, string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if err := buildMultipart(writer, hash); err != nil {
+		return nil, "", err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return body, writer.FormDataContentType(), nil
+}
+
+type namedReader interface {
+	io.Reader
+	Name() string
+}
+
+func buildMultipart(writer *multipart.Writer, hash map[string]interface{}) error {
+	return flattenParams(hash, "", func(key string, value interface{}) error {
+		switch vv := value.(type) {
+		case nil:
+			return writer.WriteField(key, "")
+
+		case string:
+			return writer.WriteField(key, vv)
+
+		case *File:
+			f, err := vv.Open()
+
+			if err != nil {
+				return err
+			}
+
+			defer f.Close()
+
+			return writeFilePart(writer, key, vv.Filename, vv.ContentType, f)
+
+		case namedReader:
+			return writeFilePart(writer, key, filepath.Base(vv.Name()), "", vv)
+		}
+
+		return fmt.Errorf("Unsupported type '%T' for key '%s'", value, key)
+	})
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func writeFilePart(writer *multipart.Writer, name, filename, contentType string, r io.Reader) error {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(name), quoteEscaper.Replace(filename)))
+	header.Set("Content-Type", contentType)
+
+	part, err := writer.CreatePart(header)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(part, r)
+	return err
+}
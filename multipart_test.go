@@ -0,0 +1,242 @@
+package qs
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func multipartBody(t *testing.T, build func(w *multipart.Writer)) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	build(writer)
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return body, writer.Boundary()
+}
+
+func readFile(t *testing.T, file *File) string {
+	f, err := file.Open()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(content)
+}
+
+func TestUnmarshalMultipart(t *testing.T) {
+	body, boundary := multipartBody(t, func(w *multipart.Writer) {
+		w.WriteField("user[name]", "Derek")
+		w.WriteField("user[tags][]", "a")
+		w.WriteField("user[tags][]", "b")
+
+		part, _ := w.CreateFormFile("user[avatar]", "avatar.png")
+		part.Write([]byte("png data"))
+	})
+
+	params, err := UnmarshalMultipart(body, boundary, 1<<20)
+
+	if assert.NoError(t, err) {
+		user := params["user"].(map[string]interface{})
+
+		assert.Equal(t, user["name"], "Derek")
+		assert.Equal(t, user["tags"], []interface{}{"a", "b"})
+
+		if avatar, ok := user["avatar"].(*File); assert.True(t, ok) {
+			assert.Equal(t, avatar.Filename, "avatar.png")
+			assert.Equal(t, avatar.ContentType, "application/octet-stream")
+			assert.Equal(t, avatar.Size, int64(8))
+			assert.Equal(t, readFile(t, avatar), "png data")
+		}
+	}
+}
+
+func TestUnmarshalMultipartSpillsToDisk(t *testing.T) {
+	body, boundary := multipartBody(t, func(w *multipart.Writer) {
+		part, _ := w.CreateFormFile("photos[][file]", "small.txt")
+		part.Write([]byte("1234"))
+
+		part, _ = w.CreateFormFile("photos[][file]", "large.txt")
+		part.Write([]byte("123456789"))
+	})
+
+	params, err := UnmarshalMultipart(body, boundary, 8)
+
+	if assert.NoError(t, err) {
+		photos := params["photos"].([]interface{})
+		small := photos[0].(map[string]interface{})["file"].(*File)
+		large := photos[1].(map[string]interface{})["file"].(*File)
+
+		assert.Empty(t, small.tmpfile)
+		assert.Equal(t, readFile(t, small), "1234")
+
+		if assert.NotEmpty(t, large.tmpfile) {
+			tmpfile := large.tmpfile
+
+			assert.Equal(t, large.Size, int64(9))
+			assert.Equal(t, readFile(t, large), "123456789")
+			assert.NoError(t, RemoveFiles(params))
+
+			_, err := os.Stat(tmpfile)
+			assert.True(t, os.IsNotExist(err))
+		}
+	}
+}
+
+func TestUnmarshalMultipartRemovesReplacedFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+
+	body, boundary := multipartBody(t, func(w *multipart.Writer) {
+		part, _ := w.CreateFormFile("avatar", "first.png")
+		part.Write([]byte("123456789"))
+
+		part, _ = w.CreateFormFile("avatar", "second.png")
+		part.Write([]byte("987654321"))
+	})
+
+	params, err := UnmarshalMultipart(body, boundary, 8)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, params["avatar"].(*File).Filename, "second.png")
+
+		tmpfiles, _ := filepath.Glob(filepath.Join(dir, "qs-multipart-*"))
+		assert.Len(t, tmpfiles, 1)
+
+		assert.NoError(t, RemoveFiles(params))
+
+		tmpfiles, _ = filepath.Glob(filepath.Join(dir, "qs-multipart-*"))
+		assert.Empty(t, tmpfiles)
+	}
+
+	body, boundary = multipartBody(t, func(w *multipart.Writer) {
+		part, _ := w.CreateFormFile("x[y]", "large.txt")
+		part.Write([]byte("123456789"))
+
+		w.WriteField("x[]", "2")
+	})
+
+	_, err = UnmarshalMultipart(body, boundary, 8)
+	assert.Error(t, err)
+
+	tmpfiles, _ := filepath.Glob(filepath.Join(dir, "qs-multipart-*"))
+	assert.Empty(t, tmpfiles)
+}
+
+func TestUnmarshalMultipartErrors(t *testing.T) {
+	body, boundary := multipartBody(t, func(w *multipart.Writer) {
+		w.WriteField("x[y]", "1")
+		w.WriteField("x[]", "2")
+	})
+
+	_, err := UnmarshalMultipart(body, boundary, 1<<20)
+	assert.Error(t, err)
+
+	_, err = UnmarshalMultipart(bytes.NewBufferString("garbage"), "boundary", 1<<20)
+	assert.Error(t, err)
+}
+
+func TestMarshalMultipart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resume.pdf")
+
+	if err := os.WriteFile(path, []byte("pdf data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resume, err := os.Open(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resume.Close()
+
+	avatar := &File{Filename: "avatar.png", ContentType: "image/png", content: []byte("png data")}
+
+	body, contentType, err := MarshalMultipart(map[string]interface{}{
+		"user": map[string]interface{}{
+			"name":      "Derek",
+			"nickname":  nil,
+			"avatar":    avatar,
+			"resume":    resume,
+			"addresses": []interface{}{map[string]interface{}{"city": "Sao Paulo"}},
+		},
+	})
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	mediaType, parameters, err := mime.ParseMediaType(contentType)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, mediaType, "multipart/form-data")
+	}
+
+	params, err := UnmarshalMultipart(body, parameters["boundary"], 1<<20)
+
+	if assert.NoError(t, err) {
+		user := params["user"].(map[string]interface{})
+
+		assert.Equal(t, user["name"], "Derek")
+		assert.Equal(t, user["nickname"], "")
+		assert.Equal(t, user["addresses"], []interface{}{map[string]interface{}{"city": "Sao Paulo"}})
+
+		if file, ok := user["avatar"].(*File); assert.True(t, ok) {
+			assert.Equal(t, file.Filename, "avatar.png")
+			assert.Equal(t, file.ContentType, "image/png")
+			assert.Equal(t, readFile(t, file), "png data")
+		}
+
+		if file, ok := user["resume"].(*File); assert.True(t, ok) {
+			assert.Equal(t, file.Filename, "resume.pdf")
+			assert.Equal(t, file.ContentType, "application/octet-stream")
+			assert.Equal(t, readFile(t, file), "pdf data")
+		}
+	}
+
+	_, _, err = MarshalMultipart(map[string]interface{}{"count": 1})
+	assert.Error(t, err)
+}
+
+func TestMarshalMultipartEscapesFilename(t *testing.T) {
+	file := &File{Filename: `say "hi"\now.txt`, content: []byte("hi")}
+
+	body, contentType, err := MarshalMultipart(map[string]interface{}{"doc": map[string]interface{}{"file": file}})
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, parameters, err := mime.ParseMediaType(contentType)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	part, err := multipart.NewReader(body, parameters["boundary"]).NextPart()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, part.Header.Get("Content-Disposition"), `form-data; name="doc[file]"; filename="say \"hi\"\\now.txt"`)
+		assert.Equal(t, part.Header.Get("Content-Type"), "application/octet-stream")
+	}
+}
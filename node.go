@@ -0,0 +1,456 @@
+package qs
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ArrayFormat selects how NodeDialect.Marshal writes array members.
+type ArrayFormat int
+
+const (
+	// ArrayFormatIndices writes a[0]=b&a[1]=c.
+	ArrayFormatIndices ArrayFormat = iota
+	// ArrayFormatBrackets writes a[]=b&a[]=c.
+	ArrayFormatBrackets
+	// ArrayFormatRepeat writes a=b&a=c.
+	ArrayFormatRepeat
+)
+
+// NodeDialect mirrors the parse and stringify behavior of the npm qs
+// package. Field names follow the qs option names.
+type NodeDialect struct {
+	// ArrayLimit is the highest index parsed as an array member. Larger
+	// indexes become hash keys.
+	ArrayLimit int
+	// Depth is the maximum number of bracket segments parsed in a key.
+	// Remaining segments are kept as a literal key.
+	Depth int
+	// ParameterLimit caps the number of parsed components. Zero means no
+	// limit.
+	ParameterLimit int
+	// AllowDots enables a.b notation in addition to a[b].
+	AllowDots bool
+	// StrictNullHandling distinguishes a (nil) from a= (empty string).
+	StrictNullHandling bool
+	// SkipNulls omits nil values when marshaling.
+	SkipNulls bool
+	// EncodeValuesOnly leaves keys unescaped when marshaling.
+	EncodeValuesOnly bool
+	ArrayFormat      ArrayFormat
+}
+
+// Node is a NodeDialect configured with the qs defaults.
+var Node = NodeDialect{
+	ArrayLimit:     20,
+	Depth:          5,
+	ParameterLimit: 1000,
+	ArrayFormat:    ArrayFormatIndices,
+}
+
+var nodeBracketRegex = regexp.MustCompile(`\[[^\[\]]*\]`)
+var nodeDotRegex = regexp.MustCompile(`\.([^.\[]+)`)
+
+// sparseArray models a JavaScript array while parsing, where members may be
+// assigned at arbitrary indexes and holes are compacted away at the end.
+type sparseArray struct {
+	items  map[int]interface{}
+	length int
+}
+
+func newSparseArray(items ...interface{}) *sparseArray {
+	array := &sparseArray{items: map[int]interface{}{}}
+
+	for _, item := range items {
+		array.push(item)
+	}
+
+	return array
+}
+
+func (a *sparseArray) set(i int, v interface{}) {
+	a.items[i] = v
+
+	if i >= a.length {
+		a.length = i + 1
+	}
+}
+
+func (a *sparseArray) push(v interface{}) {
+	a.set(a.length, v)
+}
+
+func (a *sparseArray) indexes() []int {
+	indexes := make([]int, 0, len(a.items))
+
+	for i := range a.items {
+		indexes = append(indexes, i)
+	}
+
+	sort.Ints(indexes)
+	return indexes
+}
+
+func (a *sparseArray) toHash() map[string]interface{} {
+	hash := map[string]interface{}{}
+
+	for i, v := range a.items {
+		hash[strconv.Itoa(i)] = v
+	}
+
+	return hash
+}
+
+func (d NodeDialect) Unmarshal(qs string) (map[string]interface{}, error) {
+	params := map[string]interface{}{}
+
+	if qs == "" {
+		return params, nil
+	}
+
+	parts := strings.Split(qs, "&")
+
+	if d.ParameterLimit > 0 && len(parts) > d.ParameterLimit {
+		parts = parts[:d.ParameterLimit]
+	}
+
+	keys := []string{}
+	values := map[string]interface{}{}
+
+	for _, part := range parts {
+		pos := strings.Index(part, "]=")
+
+		if pos == -1 {
+			pos = strings.Index(part, "=")
+		} else {
+			pos++
+		}
+
+		key := ""
+		value := interface{}(nil)
+
+		if pos == -1 {
+			key = queryUnescape(part)
+
+			if !d.StrictNullHandling {
+				value = ""
+			}
+		} else {
+			key = queryUnescape(part[:pos])
+			value = queryUnescape(part[pos+1:])
+		}
+
+		if existing, ok := values[key]; ok {
+			values[key] = nodeCombine(existing, value)
+		} else {
+			keys = append(keys, key)
+			values[key] = value
+		}
+	}
+
+	merged := interface{}(params)
+
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		merged = nodeMerge(merged, d.parseObject(d.splitKey(key), values[key]))
+	}
+
+	return nodeCompact(merged).(map[string]interface{}), nil
+}
+
+// queryUnescape decodes s like qs does, keeping malformed percent escapes
+// as they are while still turning + into a space.
+func queryUnescape(s string) string {
+	s = strings.ReplaceAll(s, "+", " ")
+
+	if unesc, err := url.PathUnescape(s); err == nil {
+		return unesc
+	}
+
+	return s
+}
+
+func nodeCombine(a, b interface{}) interface{} {
+	if array, ok := a.(*sparseArray); ok {
+		array.push(b)
+		return array
+	}
+
+	return newSparseArray(a, b)
+}
+
+func (d NodeDialect) splitKey(key string) []string {
+	if d.AllowDots {
+		key = nodeDotRegex.ReplaceAllString(key, "[$1]")
+	}
+
+	if d.Depth <= 0 {
+		return []string{key}
+	}
+
+	chain := []string{}
+	segments := nodeBracketRegex.FindAllStringIndex(key, -1)
+
+	if len(segments) == 0 {
+		return append(chain, key)
+	}
+
+	if parent := key[:segments[0][0]]; parent != "" {
+		chain = append(chain, parent)
+	}
+
+	for i, segment := range segments {
+		if i == d.Depth {
+			return append(chain, "["+key[segment[0]:]+"]")
+		}
+
+		chain = append(chain, key[segment[0]:segment[1]])
+	}
+
+	return chain
+}
+
+func (d NodeDialect) parseObject(chain []string, value interface{}) interface{} {
+	leaf := value
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		root := chain[i]
+
+		if root == "[]" {
+			if array, ok := leaf.(*sparseArray); ok {
+				concat := newSparseArray()
+
+				for _, j := range array.indexes() {
+					concat.set(j, array.items[j])
+				}
+
+				leaf = concat
+			} else {
+				leaf = newSparseArray(leaf)
+			}
+
+			continue
+		}
+
+		clean := root
+
+		if strings.HasPrefix(root, "[") && strings.HasSuffix(root, "]") {
+			clean = root[1 : len(root)-1]
+		}
+
+		index, err := strconv.Atoi(clean)
+
+		if err == nil && root != clean && strconv.Itoa(index) == clean && index >= 0 && index <= d.ArrayLimit {
+			array := newSparseArray()
+			array.set(index, leaf)
+			leaf = array
+		} else {
+			leaf = map[string]interface{}{clean: leaf}
+		}
+	}
+
+	return leaf
+}
+
+func nodeIsObject(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, *sparseArray:
+		return true
+	}
+
+	return false
+}
+
+// nodeMerge follows the merge rules of qs' utils.merge, including the
+// conversion of arrays into hashes when they receive non-index keys.
+func nodeMerge(target, source interface{}) interface{} {
+	if source == nil || source == "" {
+		return target
+	}
+
+	if !nodeIsObject(source) {
+		switch tt := target.(type) {
+		case *sparseArray:
+			tt.push(source)
+			return tt
+		case map[string]interface{}:
+			tt[fmt.Sprint(source)] = true
+			return tt
+		}
+
+		return newSparseArray(target, source)
+	}
+
+	if !nodeIsObject(target) {
+		array := newSparseArray(target)
+
+		if sa, ok := source.(*sparseArray); ok {
+			for _, i := range sa.indexes() {
+				array.push(sa.items[i])
+			}
+		} else {
+			array.push(source)
+		}
+
+		return array
+	}
+
+	ta, targetIsArray := target.(*sparseArray)
+	sa, sourceIsArray := source.(*sparseArray)
+
+	if targetIsArray && sourceIsArray {
+		for _, i := range sa.indexes() {
+			item := sa.items[i]
+
+			if existing, ok := ta.items[i]; ok {
+				if nodeIsObject(existing) && nodeIsObject(item) {
+					ta.items[i] = nodeMerge(existing, item)
+				} else {
+					ta.push(item)
+				}
+			} else {
+				ta.set(i, item)
+			}
+		}
+
+		return ta
+	}
+
+	hash, ok := target.(map[string]interface{})
+
+	if !ok {
+		hash = ta.toHash()
+	}
+
+	if sourceIsArray {
+		for _, i := range sa.indexes() {
+			nodeMergeKey(hash, strconv.Itoa(i), sa.items[i])
+		}
+	} else {
+		for k, v := range source.(map[string]interface{}) {
+			nodeMergeKey(hash, k, v)
+		}
+	}
+
+	return hash
+}
+
+func nodeMergeKey(hash map[string]interface{}, k string, v interface{}) {
+	if existing, ok := hash[k]; ok {
+		hash[k] = nodeMerge(existing, v)
+	} else {
+		hash[k] = v
+	}
+}
+
+func nodeCompact(value interface{}) interface{} {
+	switch vv := value.(type) {
+	case *sparseArray:
+		array := make([]interface{}, 0, len(vv.items))
+
+		for _, i := range vv.indexes() {
+			array = append(array, nodeCompact(vv.items[i]))
+		}
+
+		return array
+
+	case map[string]interface{}:
+		for k, v := range vv {
+			vv[k] = nodeCompact(v)
+		}
+	}
+
+	return value
+}
+
+func (d NodeDialect) Marshal(hash map[string]interface{}) (string, error) {
+	components := []string{}
+
+	for _, k := range sortedKeys(hash) {
+		if d.SkipNulls && hash[k] == nil {
+			continue
+		}
+
+		components = append(components, d.stringify(hash[k], k)...)
+	}
+
+	return strings.Join(components, "&"), nil
+}
+
+func (d NodeDialect) stringify(value interface{}, prefix string) []string {
+	key := prefix
+
+	if !d.EncodeValuesOnly {
+		key = escapeRFC3986(prefix)
+	}
+
+	switch vv := value.(type) {
+	case nil:
+		if d.StrictNullHandling {
+			return []string{key}
+		}
+
+		return []string{key + "="}
+
+	case []interface{}:
+		components := []string{}
+
+		for i, v := range vv {
+			if d.SkipNulls && v == nil {
+				continue
+			}
+
+			childPrefix := prefix
+
+			switch d.ArrayFormat {
+			case ArrayFormatIndices:
+				childPrefix = prefix + "[" + strconv.Itoa(i) + "]"
+			case ArrayFormatBrackets:
+				childPrefix = prefix + "[]"
+			}
+
+			components = append(components, d.stringify(v, childPrefix)...)
+		}
+
+		return components
+
+	case map[string]interface{}:
+		components := []string{}
+
+		for _, k := range sortedKeys(vv) {
+			if d.SkipNulls && vv[k] == nil {
+				continue
+			}
+
+			childPrefix := prefix + "[" + k + "]"
+
+			if d.AllowDots {
+				childPrefix = prefix + "." + k
+			}
+
+			components = append(components, d.stringify(vv[k], childPrefix)...)
+		}
+
+		return components
+	}
+
+	return []string{key + "=" + escapeRFC3986(fmt.Sprint(value))}
+}
+
+func sortedKeys(hash map[string]interface{}) []string {
+	keys := make([]string, 0, len(hash))
+
+	for k := range hash {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
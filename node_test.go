@@ -0,0 +1,188 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeUnmarshal(t *testing.T) {
+	hash, err := Node.Unmarshal("")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{})
+	}
+
+	hash, err = Node.Unmarshal("a")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": ""})
+	}
+
+	hash, err = Node.Unmarshal("a=b&a=c")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": []interface{}{"b", "c"}})
+	}
+
+	hash, err = Node.Unmarshal("a[b][c]=d")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": "d"}}})
+	}
+
+	hash, err = Node.Unmarshal("a%5Bb%5D=c%20d")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": map[string]interface{}{"b": "c d"}})
+	}
+
+	hash, err = Node.Unmarshal("a=%zz+b&c%=d+e")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": "%zz b", "c%": "d e"})
+	}
+
+	hash, err = Node.Unmarshal("a[]=b&a[]=c")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": []interface{}{"b", "c"}})
+	}
+
+	hash, err = Node.Unmarshal("a[1]=c&a[0]=b")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": []interface{}{"b", "c"}})
+	}
+
+	hash, err = Node.Unmarshal("a[1]=b&a[15]=c")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": []interface{}{"b", "c"}})
+	}
+
+	hash, err = Node.Unmarshal("a[21]=b")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": map[string]interface{}{"21": "b"}})
+	}
+
+	hash, err = Node.Unmarshal("a[0]=b&a[b]=c")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": map[string]interface{}{"0": "b", "b": "c"}})
+	}
+
+	hash, err = Node.Unmarshal("a[0][b]=c&a[0][d]=e&a[1][b]=f")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": []interface{}{
+			map[string]interface{}{"b": "c", "d": "e"},
+			map[string]interface{}{"b": "f"},
+		}})
+	}
+
+	hash, err = Node.Unmarshal("a[b][c][d][e][f][g][h]=i")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": map[string]interface{}{"d": map[string]interface{}{"e": map[string]interface{}{"f": map[string]interface{}{"[g][h]": "i"}}}}}}})
+	}
+
+	hash, err = Node.Unmarshal("a=b&c=d&e=f")
+	if assert.NoError(t, err) {
+		assert.Len(t, hash, 3)
+	}
+
+	limited := Node
+	limited.ParameterLimit = 1
+
+	hash, err = limited.Unmarshal("a=b&c=d")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": "b"})
+	}
+
+	dots := Node
+	dots.AllowDots = true
+
+	hash, err = dots.Unmarshal("a.b=c&a.d[]=e")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": map[string]interface{}{"b": "c", "d": []interface{}{"e"}}})
+	}
+
+	strict := Node
+	strict.StrictNullHandling = true
+
+	hash, err = strict.Unmarshal("a&b=")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{"a": nil, "b": ""})
+	}
+}
+
+func TestNodeMarshal(t *testing.T) {
+	querystring, err := Node.Marshal(map[string]interface{}{"a": "b", "c": "d e"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a=b&c=d%20e")
+	}
+
+	querystring, err = Node.Marshal(map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{"c", "d"}}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a%5Bb%5D%5B0%5D=c&a%5Bb%5D%5B1%5D=d")
+	}
+
+	querystring, err = Node.Marshal(map[string]interface{}{"a": nil, "b": []interface{}{}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a=")
+	}
+
+	options := Node
+	options.EncodeValuesOnly = true
+
+	querystring, err = options.Marshal(map[string]interface{}{"a": []interface{}{map[string]interface{}{"b": "c"}}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a[0][b]=c")
+	}
+
+	options.ArrayFormat = ArrayFormatBrackets
+
+	querystring, err = options.Marshal(map[string]interface{}{"a": []interface{}{"b", "c"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a[]=b&a[]=c")
+	}
+
+	options.ArrayFormat = ArrayFormatRepeat
+
+	querystring, err = options.Marshal(map[string]interface{}{"a": []interface{}{"b", "c"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a=b&a=c")
+	}
+
+	options.AllowDots = true
+
+	querystring, err = options.Marshal(map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": "d"}}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a.b.c=d")
+	}
+
+	options.SkipNulls = true
+
+	querystring, err = options.Marshal(map[string]interface{}{"a": "b", "c": nil})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a=b")
+	}
+
+	options.SkipNulls = false
+	options.StrictNullHandling = true
+
+	querystring, err = options.Marshal(map[string]interface{}{"a": "b", "c": nil})
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a=b&c")
+	}
+}
+
+func TestNodeRoundTrip(t *testing.T) {
+	testables := []map[string]interface{}{
+		map[string]interface{}{"foo": "bar"},
+		map[string]interface{}{"foo": []interface{}{"1", "2"}},
+		map[string]interface{}{"x": map[string]interface{}{"y": []interface{}{map[string]interface{}{"z": "1", "w": "a"}, map[string]interface{}{"z": "2"}}}},
+		map[string]interface{}{"my weird field": "q1!2\"'w$5&7/z8)?"},
+	}
+
+	for _, v := range testables {
+		querystring, err := Node.Marshal(v)
+
+		if assert.NoError(t, err) {
+			hash, err := Node.Unmarshal(querystring)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, hash, v)
+			}
+		}
+	}
+}
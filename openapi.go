@@ -0,0 +1,313 @@
+package qs
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Style is an OpenAPI 3 query parameter serialization style.
+type Style string
+
+const (
+	StyleForm           Style = "form"
+	StyleSpaceDelimited Style = "spaceDelimited"
+	StylePipeDelimited  Style = "pipeDelimited"
+	StyleDeepObject     Style = "deepObject"
+)
+
+// ParameterType is the shape of a parameter schema, which decides how a
+// serialized value is split back into parts.
+type ParameterType int
+
+const (
+	PrimitiveParameter ParameterType = iota
+	ArrayParameter
+	ObjectParameter
+)
+
+// Parameter describes a single OpenAPI query parameter. Primitive values are
+// strings, arrays are []interface{} and objects are map[string]interface{},
+// as returned by Unmarshal.
+type Parameter struct {
+	Name    string
+	Style   Style
+	Explode bool
+	Type    ParameterType
+}
+
+func (p Parameter) delimiter() (string, error) {
+	switch p.Style {
+	case StyleForm:
+		return ",", nil
+	case StyleSpaceDelimited:
+		return "%20", nil
+	case StylePipeDelimited:
+		return "|", nil
+	case StyleDeepObject:
+		if p.Explode && p.Type == ObjectParameter {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("Style '%s' requires an exploded object for parameter '%s'", p.Style, p.Name)
+	}
+
+	return "", fmt.Errorf("Unknown style '%s' for parameter '%s'", p.Style, p.Name)
+}
+
+// Encode serializes value as the query string fragment for p.
+func (p Parameter) Encode(value interface{}) (string, error) {
+	delimiter, err := p.delimiter()
+
+	if err != nil {
+		return "", err
+	}
+
+	name := url.QueryEscape(p.Name)
+
+	switch p.Type {
+	case PrimitiveParameter:
+		str, ok := value.(string)
+
+		if !ok {
+			return "", fmt.Errorf("Expected type 'string' for parameter '%s', but got '%T'", p.Name, value)
+		}
+
+		if p.Style != StyleForm {
+			return "", fmt.Errorf("Style '%s' does not support primitive parameter '%s'", p.Style, p.Name)
+		}
+
+		return name + "=" + url.QueryEscape(str), nil
+
+	case ArrayParameter:
+		array, ok := value.([]interface{})
+
+		if !ok {
+			return "", fmt.Errorf("Expected type '[]interface{}' for parameter '%s', but got '%T'", p.Name, value)
+		}
+
+		parts, err := openAPIStrings(p.Name, array)
+
+		if err != nil {
+			return "", err
+		}
+
+		if p.Explode {
+			components := make([]string, len(parts))
+
+			for i, part := range parts {
+				components[i] = name + "=" + part
+			}
+
+			return strings.Join(components, "&"), nil
+		}
+
+		return name + "=" + strings.Join(parts, delimiter), nil
+
+	case ObjectParameter:
+		hash, ok := value.(map[string]interface{})
+
+		if !ok {
+			return "", fmt.Errorf("Expected type 'map[string]interface{}' for parameter '%s', but got '%T'", p.Name, value)
+		}
+
+		if p.Style == StyleDeepObject {
+			return buildNestedQuery(hash, name)
+		}
+
+		keys := sortedKeys(hash)
+		values := make([]interface{}, len(keys))
+
+		for i, k := range keys {
+			values[i] = hash[k]
+		}
+
+		parts, err := openAPIStrings(p.Name, values)
+
+		if err != nil {
+			return "", err
+		}
+
+		components := make([]string, len(keys))
+
+		for i, k := range keys {
+			if p.Explode {
+				components[i] = url.QueryEscape(k) + "=" + parts[i]
+			} else {
+				components[i] = url.QueryEscape(k) + delimiter + parts[i]
+			}
+		}
+
+		if p.Explode {
+			return strings.Join(components, "&"), nil
+		}
+
+		return name + "=" + strings.Join(components, delimiter), nil
+	}
+
+	return "", fmt.Errorf("Unknown type '%d' for parameter '%s'", p.Type, p.Name)
+}
+
+func openAPIStrings(name string, values []interface{}) ([]string, error) {
+	parts := make([]string, len(values))
+
+	for i, v := range values {
+		str, ok := v.(string)
+
+		if !ok {
+			return nil, fmt.Errorf("Expected type 'string' for member of parameter '%s', but got '%T'", name, v)
+		}
+
+		parts[i] = url.QueryEscape(str)
+	}
+
+	return parts, nil
+}
+
+type openAPIComponent struct {
+	key string
+	raw string
+}
+
+// Decode extracts the value of p from a query string. A missing parameter
+// decodes to nil.
+func (p Parameter) Decode(qs string) (interface{}, error) {
+	delimiter, err := p.delimiter()
+
+	if err != nil {
+		return nil, err
+	}
+
+	components := []openAPIComponent{}
+
+	for _, c := range strings.Split(qs, "&") {
+		if c == "" {
+			continue
+		}
+
+		tuple := strings.SplitN(c, "=", 2)
+		component := openAPIComponent{key: queryUnescape(tuple[0])}
+
+		if len(tuple) > 1 {
+			component.raw = tuple[1]
+		}
+
+		components = append(components, component)
+	}
+
+	switch p.Type {
+	case PrimitiveParameter:
+		if p.Style != StyleForm {
+			return nil, fmt.Errorf("Style '%s' does not support primitive parameter '%s'", p.Style, p.Name)
+		}
+
+		value := interface{}(nil)
+
+		for _, c := range components {
+			if c.key == p.Name {
+				value = queryUnescape(c.raw)
+			}
+		}
+
+		return value, nil
+
+	case ArrayParameter:
+		if p.Explode {
+			array := []interface{}{}
+
+			for _, c := range components {
+				if c.key == p.Name {
+					array = append(array, queryUnescape(c.raw))
+				}
+			}
+
+			if len(array) == 0 {
+				return nil, nil
+			}
+
+			return array, nil
+		}
+
+		for i := len(components) - 1; i >= 0; i-- {
+			if components[i].key == p.Name {
+				return openAPISplit(components[i].raw, delimiter), nil
+			}
+		}
+
+		return nil, nil
+
+	case ObjectParameter:
+		if p.Style == StyleDeepObject {
+			params := map[string]interface{}{}
+
+			for _, c := range components {
+				if strings.HasPrefix(c.key, p.Name+"[") {
+					if err := normalizeParams(params, c.key, queryUnescape(c.raw)); err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			return params[p.Name], nil
+		}
+
+		hash := map[string]interface{}{}
+
+		if p.Explode {
+			for _, c := range components {
+				hash[c.key] = queryUnescape(c.raw)
+			}
+
+			return hash, nil
+		}
+
+		for i := len(components) - 1; i >= 0; i-- {
+			if components[i].key != p.Name {
+				continue
+			}
+
+			parts := openAPISplit(components[i].raw, delimiter)
+
+			if len(parts)%2 != 0 {
+				return nil, fmt.Errorf("Expected key and value pairs for parameter '%s', but got %d parts", p.Name, len(parts))
+			}
+
+			for j := 0; j < len(parts); j += 2 {
+				hash[parts[j].(string)] = parts[j+1]
+			}
+
+			return hash, nil
+		}
+
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("Unknown type '%d' for parameter '%s'", p.Type, p.Name)
+}
+
+// openAPIDelimiters lists the forms of delimiter accepted when decoding.
+// Form encoding writes spaces as +, and | is not allowed in URLs, so clients
+// such as url.Values.Encode escape it as %7C. Values of pipeDelimited
+// parameters therefore cannot contain a pipe.
+var openAPIDelimiters = map[string][]string{
+	"%20": {"+"},
+	"|":   {"%7C", "%7c"},
+}
+
+func openAPISplit(raw string, delimiter string) []interface{} {
+	array := []interface{}{}
+
+	if raw == "" {
+		return array
+	}
+
+	for _, alternative := range openAPIDelimiters[delimiter] {
+		raw = strings.ReplaceAll(raw, alternative, delimiter)
+	}
+
+	for _, part := range strings.Split(raw, delimiter) {
+		array = append(array, queryUnescape(part))
+	}
+
+	return array
+}
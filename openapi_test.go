@@ -0,0 +1,95 @@
+package qs
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParameterEncode(t *testing.T) {
+	array := []interface{}{"3", "4", "5"}
+	object := map[string]interface{}{"role": "admin", "firstName": "Alex"}
+
+	testables := []struct {
+		parameter Parameter
+		value     interface{}
+		expected  string
+	}{
+		{Parameter{"id", StyleForm, true, PrimitiveParameter}, "5", "id=5"},
+		{Parameter{"id", StyleForm, false, PrimitiveParameter}, "a b", "id=a+b"},
+		{Parameter{"id", StyleForm, true, ArrayParameter}, array, "id=3&id=4&id=5"},
+		{Parameter{"id", StyleForm, false, ArrayParameter}, array, "id=3,4,5"},
+		{Parameter{"id", StyleForm, true, ObjectParameter}, object, "firstName=Alex&role=admin"},
+		{Parameter{"id", StyleForm, false, ObjectParameter}, object, "id=firstName,Alex,role,admin"},
+		{Parameter{"id", StyleSpaceDelimited, true, ArrayParameter}, array, "id=3&id=4&id=5"},
+		{Parameter{"id", StyleSpaceDelimited, false, ArrayParameter}, array, "id=3%204%205"},
+		{Parameter{"id", StylePipeDelimited, false, ArrayParameter}, array, "id=3|4|5"},
+		{Parameter{"id", StylePipeDelimited, false, ObjectParameter}, object, "id=firstName|Alex|role|admin"},
+		{Parameter{"id", StyleDeepObject, true, ObjectParameter}, map[string]interface{}{"role": "admin"}, "id[role]=admin"},
+		{Parameter{"id", StyleDeepObject, true, ObjectParameter}, map[string]interface{}{"role": "admin", "firstName": "Alex", "tags": map[string]interface{}{"z": "1", "a": "2"}}, "id[firstName]=Alex&id[role]=admin&id[tags][a]=2&id[tags][z]=1"},
+	}
+
+	for _, testable := range testables {
+		querystring, err := testable.parameter.Encode(testable.value)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, querystring, testable.expected)
+
+			value, err := testable.parameter.Decode(querystring)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, value, testable.value)
+			}
+		}
+	}
+
+	_, err := Parameter{"id", StyleDeepObject, false, ObjectParameter}.Encode(object)
+	assert.Error(t, err)
+
+	_, err = Parameter{"id", StylePipeDelimited, false, PrimitiveParameter}.Encode("5")
+	assert.Error(t, err)
+
+	_, err = Parameter{"id", StyleForm, false, ArrayParameter}.Encode("5")
+	assert.Error(t, err)
+
+	_, err = Parameter{"id", "matrix", false, PrimitiveParameter}.Encode("5")
+	assert.Error(t, err)
+}
+
+func TestParameterDecode(t *testing.T) {
+	value, err := Parameter{"id", StyleForm, false, ArrayParameter}.Decode("id=a%2Cb,c&other=1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, value, []interface{}{"a,b", "c"})
+	}
+
+	value, err = Parameter{"id", StyleSpaceDelimited, false, ArrayParameter}.Decode("id=3+4%205")
+	if assert.NoError(t, err) {
+		assert.Equal(t, value, []interface{}{"3", "4", "5"})
+	}
+
+	value, err = Parameter{"id", StylePipeDelimited, false, ArrayParameter}.Decode("id=3%7C4|5%7c6")
+	if assert.NoError(t, err) {
+		assert.Equal(t, value, []interface{}{"3", "4", "5", "6"})
+	}
+
+	values := url.Values{"id": {"3|4|5"}}
+
+	value, err = Parameter{"id", StylePipeDelimited, false, ArrayParameter}.Decode(values.Encode())
+	if assert.NoError(t, err) {
+		assert.Equal(t, value, []interface{}{"3", "4", "5"})
+	}
+
+	value, err = Parameter{"id", StyleDeepObject, true, ObjectParameter}.Decode("id%5Brole%5D=admin&id[tags][]=a&id[tags][]=b&x=1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, value, map[string]interface{}{"role": "admin", "tags": []interface{}{"a", "b"}})
+	}
+
+	value, err = Parameter{"id", StyleForm, true, PrimitiveParameter}.Decode("other=1")
+	if assert.NoError(t, err) {
+		assert.Nil(t, value)
+	}
+
+	_, err = Parameter{"id", StyleForm, false, ObjectParameter}.Decode("id=role,admin,firstName")
+	assert.Error(t, err)
+}
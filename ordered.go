@@ -0,0 +1,128 @@
+package qs
+
+// OrderedMap is a hash that remembers the order in which its keys were
+// first set. UnmarshalOrdered uses it at every nesting level. A nil
+// OrderedMap reads as empty.
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedMap returns an empty OrderedMap.
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{values: map[string]interface{}{}}
+}
+
+// Get returns the value stored at key and whether it exists.
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Set stores value at key. Existing keys keep their position.
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+
+	m.values[key] = value
+}
+
+// Delete removes key, moving the keys after it one position back.
+func (m *OrderedMap) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+
+	delete(m.values, key)
+
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the keys in insertion order.
+func (m *OrderedMap) Keys() []string {
+	if m == nil {
+		return []string{}
+	}
+
+	return append([]string(nil), m.keys...)
+}
+
+// Len returns the number of keys.
+func (m *OrderedMap) Len() int {
+	if m == nil {
+		return 0
+	}
+
+	return len(m.keys)
+}
+
+// ToMap converts m and every nested OrderedMap to map[string]interface{}.
+func (m *OrderedMap) ToMap() map[string]interface{} {
+	return orderedToMap(m).(map[string]interface{})
+}
+
+func orderedToMap(value interface{}) interface{} {
+	switch vv := value.(type) {
+	case *OrderedMap:
+		hash := map[string]interface{}{}
+
+		for _, k := range vv.Keys() {
+			v, _ := vv.Get(k)
+			hash[k] = orderedToMap(v)
+		}
+
+		return hash
+
+	case []interface{}:
+		array := make([]interface{}, len(vv))
+
+		for i, v := range vv {
+			array[i] = orderedToMap(v)
+		}
+
+		return array
+	}
+
+	return value
+}
+
+var orderedHashKind = hashKind{
+	name: "*OrderedMap",
+	empty: func() paramsHash {
+		return NewOrderedMap()
+	},
+	wrap: func(value interface{}) (paramsHash, bool) {
+		hash, ok := value.(*OrderedMap)
+		return hash, ok
+	},
+	unwrap: func(hash paramsHash) interface{} {
+		return hash
+	},
+}
+
+// UnmarshalOrdered decodes qs like Unmarshal, but builds an OrderedMap at
+// every nesting level, keeping keys in the order they first appear.
+func UnmarshalOrdered(qs string) (*OrderedMap, error) {
+	params := NewOrderedMap()
+
+	if err := unmarshalInto(qs, params, orderedHashKind); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// MarshalOrdered encodes m like Marshal, writing keys in their order.
+func MarshalOrdered(m *OrderedMap) (string, error) {
+	return buildNestedQuery(m, "")
+}
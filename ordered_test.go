@@ -0,0 +1,89 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalOrdered(t *testing.T) {
+	query := "z=1&a=2&x[y][][z]=1&x[y][][w]=a&x[y][][z]=2&m[c]=1&m[b]=2&m[a][]=3&z=3"
+
+	params, err := UnmarshalOrdered(query)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, params.Keys(), []string{"z", "a", "x", "m"})
+	assert.Equal(t, params.Len(), 4)
+
+	value, ok := params.Get("z")
+	assert.True(t, ok)
+	assert.Equal(t, value, "3")
+
+	m, _ := params.Get("m")
+	assert.Equal(t, m.(*OrderedMap).Keys(), []string{"c", "b", "a"})
+
+	hash, err := Unmarshal(query)
+	if assert.NoError(t, err) {
+		assert.Equal(t, params.ToMap(), hash)
+	}
+
+	querystring, err := MarshalOrdered(params)
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "z=3&a=2&x[y][][z]=1&x[y][][w]=a&x[y][][z]=2&m[c]=1&m[b]=2&m[a][]=3")
+	}
+
+	_, err = UnmarshalOrdered("x[y]=1&x[y]z=2")
+	assert.Error(t, err)
+
+	_, err = UnmarshalOrdered("x[y]=1&x[]=1")
+	assert.Error(t, err)
+}
+
+func TestOrderedMap(t *testing.T) {
+	m := NewOrderedMap()
+	m.Set("b", "1")
+	m.Set("a", "2")
+	m.Set("c", "3")
+	m.Set("b", "4")
+	m.Delete("a")
+	m.Delete("missing")
+
+	assert.Equal(t, m.Keys(), []string{"b", "c"})
+
+	querystring, err := MarshalOrdered(m)
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "b=4&c=3")
+	}
+
+	params, err := UnmarshalOrdered(querystring)
+	if assert.NoError(t, err) {
+		assert.Equal(t, params, m)
+	}
+}
+
+func TestOrderedMapNil(t *testing.T) {
+	var m *OrderedMap
+
+	_, ok := m.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, m.Len(), 0)
+	assert.Equal(t, m.Keys(), []string{})
+	assert.Equal(t, m.ToMap(), map[string]interface{}{})
+
+	querystring, err := MarshalOrdered(m)
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "")
+	}
+
+	parent := NewOrderedMap()
+	parent.Set("a", "1")
+	parent.Set("b", m)
+
+	querystring, err = MarshalOrdered(parent)
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "a=1")
+	}
+}
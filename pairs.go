@@ -0,0 +1,48 @@
+//go:build go1.23
+
+package qs
+
+import "iter"
+
+// Pairs yields the bracket key and value of every leaf in hash, the same
+// pairs Marshal writes but unescaped and with hash keys in sorted order.
+// Leaves that are not strings, such as nil, yield an empty value. Pairs
+// requires Go 1.23 or later.
+func Pairs(hash map[string]interface{}) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		flattenParams(hash, "", func(key string, value interface{}) error {
+			if !yield(key, leafString(value)) {
+				return SkipAll
+			}
+
+			return nil
+		})
+	}
+}
+
+// PathPairs is like Pairs but yields the Path of every leaf, addressing
+// array members by index.
+func PathPairs(hash map[string]interface{}) iter.Seq2[Path, string] {
+	return func(yield func(Path, string) bool) {
+		Walk(hash, func(path Path, value interface{}) error {
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				return nil
+			}
+
+			if !yield(path, leafString(value)) {
+				return SkipAll
+			}
+
+			return nil
+		})
+	}
+}
+
+func leafString(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+
+	return ""
+}
@@ -0,0 +1,66 @@
+//go:build go1.23
+
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPairs(t *testing.T) {
+	hash, err := Unmarshal("x[y][][z]=1&x[y][][w]=a&x[y][][z]=2&flag&my+field=a%26b&empty[]=")
+	assert.NoError(t, err)
+
+	keys := []string{}
+	values := []string{}
+
+	for k, v := range Pairs(hash) {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+
+	assert.Equal(t, keys, []string{"empty[]", "flag", "my field", "x[y][][w]", "x[y][][z]", "x[y][][z]"})
+	assert.Equal(t, values, []string{"", "", "a&b", "a", "1", "2"})
+
+	keys = []string{}
+
+	for k := range Pairs(hash) {
+		keys = append(keys, k)
+
+		if len(keys) == 2 {
+			break
+		}
+	}
+
+	assert.Equal(t, keys, []string{"empty[]", "flag"})
+
+	for range Pairs(map[string]interface{}{}) {
+		t.Error("expected no pairs")
+	}
+}
+
+func TestPathPairs(t *testing.T) {
+	hash, err := Unmarshal("x[y][][z]=1&x[y][][z]=2&flag&list[]=a&list[]=b")
+	assert.NoError(t, err)
+
+	paths := []Path{}
+	values := []string{}
+
+	for p, v := range PathPairs(hash) {
+		paths = append(paths, p)
+		values = append(values, v)
+	}
+
+	assert.Equal(t, paths, []Path{{"flag"}, {"list", 0}, {"list", 1}, {"x", "y", 0, "z"}, {"x", "y", 1, "z"}})
+	assert.Equal(t, values, []string{"", "a", "b", "1", "2"})
+
+	paths = []Path{}
+
+	for p := range PathPairs(hash) {
+		paths = append(paths, p)
+		break
+	}
+
+	assert.Equal(t, paths, []Path{{"flag"}})
+}
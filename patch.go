@@ -0,0 +1,102 @@
+package qs
+
+import "fmt"
+
+// A Patch is a list of changes that turns one params tree into another.
+// Trailing array members are removed from the last index backwards, so
+// applying the changes in order keeps indexes valid.
+type Patch []Change
+
+// MakePatch returns the patch that turns a into b.
+func MakePatch(a, b map[string]interface{}) Patch {
+	return Patch(DiffParams(a, b))
+}
+
+// Apply applies the changes of p to tree in order. Added array members are
+// inserted at their index and removed ones shift the following members
+// back. When a change does not fit tree, Apply fails without modifying
+// tree.
+func (p Patch) Apply(tree map[string]interface{}) error {
+	trial := copyValue(tree).(map[string]interface{})
+
+	if err := p.apply(trial); err != nil {
+		return err
+	}
+
+	return p.apply(tree)
+}
+
+func (p Patch) apply(tree map[string]interface{}) error {
+	for _, c := range p {
+		if err := c.apply(tree); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c Change) apply(tree map[string]interface{}) error {
+	if len(c.Path) == 0 {
+		return fmt.Errorf("Invalid empty path")
+	}
+
+	parentPath, last := c.Path[:len(c.Path)-1], c.Path[len(c.Path)-1]
+	parent, ok := lookupPath(tree, parentPath)
+
+	if !ok {
+		return fmt.Errorf("Key '%s' not found", parentPath)
+	}
+
+	switch key := last.(type) {
+	case string:
+		hash, ok := parent.(map[string]interface{})
+
+		if !ok {
+			return fmt.Errorf("Expected type 'map[string]interface{}' for key '%s', but got '%T'", parentPath, parent)
+		}
+
+		if _, exists := hash[key]; !exists && c.Kind != Added {
+			return fmt.Errorf("Key '%s' not found", c.Path)
+		}
+
+		if c.Kind == Removed {
+			delete(hash, key)
+		} else {
+			hash[key] = copyValue(c.New)
+		}
+
+		return nil
+
+	case int:
+		array, ok := parent.([]interface{})
+
+		if !ok {
+			return fmt.Errorf("Expected type '[]interface{}' for key '%s', but got '%T'", parentPath, parent)
+		}
+
+		length := len(array)
+
+		if c.Kind == Added {
+			length++
+		}
+
+		if key < 0 || key >= length {
+			return fmt.Errorf("Index %d out of range for key '%s'", key, parentPath)
+		}
+
+		switch c.Kind {
+		case Added:
+			array = append(array[:key:key], append([]interface{}{copyValue(c.New)}, array[key:]...)...)
+		case Removed:
+			array = append(array[:key:key], array[key+1:]...)
+		default:
+			array[key] = copyValue(c.New)
+		}
+
+		_, err := setPath(tree, parentPath, 0, array)
+		return err
+	}
+
+	return fmt.Errorf("Invalid element '%v' in path '%s'", last, c.Path)
+}
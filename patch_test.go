@@ -0,0 +1,94 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatch(t *testing.T) {
+	saved, _ := Unmarshal("page=1&filter[status]=open&filter[tags][]=a&filter[tags][]=b&filter[tags][]=c&old=1")
+	edited, _ := Unmarshal("page=2&filter[status]=open&filter[tags][]=a&filter[owner]=me&new=1")
+
+	patch := MakePatch(saved, edited)
+	assert.Len(t, patch, 6)
+
+	err := patch.Apply(saved)
+	if assert.NoError(t, err) {
+		assert.Equal(t, saved, edited)
+	}
+
+	defaults, _ := Unmarshal("page=1&per=50&filter[status]=open&filter[tags][]=a&filter[tags][]=b&filter[tags][]=c&filter[tags][]=d&old=1")
+
+	err = patch.Apply(defaults)
+	if assert.NoError(t, err) {
+		assert.Equal(t, defaults, map[string]interface{}{
+			"page":   "2",
+			"per":    "50",
+			"filter": map[string]interface{}{"status": "open", "owner": "me", "tags": []interface{}{"a", "d"}},
+			"new":    "1",
+		})
+	}
+
+	edited["filter"].(map[string]interface{})["owner"] = "you"
+	assert.Equal(t, patch[0].New, "me")
+}
+
+func TestPatchArrays(t *testing.T) {
+	a := map[string]interface{}{"list": []interface{}{"a"}, "rows": []interface{}{map[string]interface{}{"id": "1"}}}
+	b := map[string]interface{}{"list": []interface{}{"a", "b", "c"}, "rows": []interface{}{map[string]interface{}{"id": "2"}}}
+
+	patch := MakePatch(a, b)
+
+	tree := map[string]interface{}{"list": []interface{}{"x", "y"}, "rows": []interface{}{map[string]interface{}{"id": "1"}, map[string]interface{}{"id": "9"}}}
+
+	err := patch.Apply(tree)
+	if assert.NoError(t, err) {
+		assert.Equal(t, tree, map[string]interface{}{
+			"list": []interface{}{"x", "b", "c", "y"},
+			"rows": []interface{}{map[string]interface{}{"id": "2"}, map[string]interface{}{"id": "9"}},
+		})
+	}
+
+	err = Patch{Change{Kind: Added, Path: Path{"list", 9}, New: "z"}}.Apply(tree)
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "Index 9 out of range for key 'list'")
+	}
+
+	err = Patch{Change{Kind: Removed, Path: Path{"list", "x"}}}.Apply(tree)
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "Expected type 'map[string]interface{}' for key 'list', but got '[]interface {}'")
+	}
+}
+
+func TestPatchErrors(t *testing.T) {
+	tree := map[string]interface{}{"a": "1"}
+
+	err := Patch{Change{Kind: Changed, Path: Path{"b"}, New: "2"}}.Apply(tree)
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "Key 'b' not found")
+	}
+
+	err = Patch{Change{Kind: Removed, Path: Path{"x", "y"}}}.Apply(tree)
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "Key 'x' not found")
+	}
+
+	err = Patch{Change{Kind: Added, Path: Path{"a", 0}, New: "2"}}.Apply(tree)
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "Expected type '[]interface{}' for key 'a', but got 'string'")
+	}
+
+	err = Patch{Change{Kind: Added, Path: Path{}}}.Apply(tree)
+	assert.Error(t, err)
+
+	err = Patch{
+		Change{Kind: Changed, Path: Path{"a"}, New: "2"},
+		Change{Kind: Added, Path: Path{"b", "c"}, New: "3"},
+	}.Apply(tree)
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "Key 'b' not found")
+	}
+
+	assert.Equal(t, tree, map[string]interface{}{"a": "1"})
+}
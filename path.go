@@ -0,0 +1,190 @@
+package qs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A Path addresses a value inside a decoded tree. Elements are string hash
+// keys or int array indexes, written as user[addresses][0][city].
+type Path []interface{}
+
+// ParsePath parses a bracket path expression. Bracketed segments made of
+// digits only are array indexes.
+func ParsePath(expr string) (Path, error) {
+	return parsePath(expr, false)
+}
+
+// wildcard is the element of a path pattern written as [], which matches
+// every member of an array.
+type wildcard struct{}
+
+func (wildcard) String() string {
+	return ""
+}
+
+func parsePath(expr string, wildcards bool) (Path, error) {
+	pos := strings.IndexByte(expr, '[')
+
+	if pos == -1 {
+		pos = len(expr)
+	}
+
+	if pos == 0 || strings.IndexByte(expr[:pos], ']') != -1 {
+		return nil, fmt.Errorf("Invalid path '%s'", expr)
+	}
+
+	path := Path{expr[:pos]}
+	rest := expr[pos:]
+
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+
+		if rest[0] != '[' || end == -1 {
+			return nil, fmt.Errorf("Invalid path '%s'", expr)
+		}
+
+		segment := rest[1:end]
+		rest = rest[end+1:]
+
+		if segment == "" && wildcards {
+			path = append(path, wildcard{})
+			continue
+		}
+
+		if segment == "" || strings.IndexByte(segment, '[') != -1 {
+			return nil, fmt.Errorf("Invalid path '%s'", expr)
+		}
+
+		if index, err := strconv.Atoi(segment); err == nil && index >= 0 && strconv.Itoa(index) == segment {
+			path = append(path, index)
+		} else {
+			path = append(path, segment)
+		}
+	}
+
+	return path, nil
+}
+
+func (p Path) String() string {
+	var b strings.Builder
+
+	for i, element := range p {
+		if i == 0 {
+			fmt.Fprint(&b, element)
+		} else {
+			fmt.Fprintf(&b, "[%v]", element)
+		}
+	}
+
+	return b.String()
+}
+
+// hashElement returns the element used to address node. Rack decodes
+// indexes such as [0] into hash keys, so an int addresses a hash by its
+// string form.
+func hashElement(node interface{}, element interface{}) interface{} {
+	if index, ok := element.(int); ok {
+		if _, ok := node.(map[string]interface{}); ok {
+			return strconv.Itoa(index)
+		}
+	}
+
+	return element
+}
+
+// lookupPath returns the value at path inside tree.
+func lookupPath(tree interface{}, path Path) (interface{}, bool) {
+	current := tree
+
+	for _, element := range path {
+		switch key := hashElement(current, element).(type) {
+		case string:
+			hash, ok := current.(map[string]interface{})
+
+			if !ok {
+				return nil, false
+			}
+
+			if current, ok = hash[key]; !ok {
+				return nil, false
+			}
+
+		case int:
+			array, ok := current.([]interface{})
+
+			if !ok || key < 0 || key >= len(array) {
+				return nil, false
+			}
+
+			current = array[key]
+
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// setPath stores value at path below node, creating missing hashes and
+// appending to arrays when the index equals their length. It returns the
+// updated node, which differs from node when an array grows.
+func setPath(node interface{}, path Path, i int, value interface{}) (interface{}, error) {
+	if i == len(path) {
+		return value, nil
+	}
+
+	switch key := hashElement(node, path[i]).(type) {
+	case string:
+		hash, ok := node.(map[string]interface{})
+
+		if node == nil {
+			hash = map[string]interface{}{}
+		} else if !ok {
+			return nil, fmt.Errorf("Expected type 'map[string]interface{}' for key '%s', but got '%T'", path[:i], node)
+		}
+
+		child, err := setPath(hash[key], path, i+1, value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		hash[key] = child
+		return hash, nil
+
+	case int:
+		array, ok := node.([]interface{})
+
+		if node != nil && !ok {
+			return nil, fmt.Errorf("Expected type '[]interface{}' for key '%s', but got '%T'", path[:i], node)
+		}
+
+		if key < 0 || key > len(array) {
+			return nil, fmt.Errorf("Index %d out of range for key '%s'", key, path[:i])
+		}
+
+		child := interface{}(nil)
+
+		if key < len(array) {
+			child = array[key]
+		}
+
+		child, err := setPath(child, path, i+1, value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if key == len(array) {
+			return append(array, child), nil
+		}
+
+		array[key] = child
+		return array, nil
+	}
+
+	return nil, fmt.Errorf("Invalid element '%v' in path '%s'", path[i], path)
+}
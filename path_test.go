@@ -0,0 +1,32 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePath(t *testing.T) {
+	path, err := ParsePath("user")
+	if assert.NoError(t, err) {
+		assert.Equal(t, path, Path{"user"})
+	}
+
+	path, err = ParsePath("user[addresses][0][city]")
+	if assert.NoError(t, err) {
+		assert.Equal(t, path, Path{"user", "addresses", 0, "city"})
+		assert.Equal(t, path.String(), "user[addresses][0][city]")
+	}
+
+	path, err = ParsePath("0[01][-1][my key]")
+	if assert.NoError(t, err) {
+		assert.Equal(t, path, Path{"0", "01", "-1", "my key"})
+	}
+
+	invalid := []string{"", "[a]", "a]", "a[]", "a[b", "a[b]c", "a[b[c]]"}
+
+	for _, expr := range invalid {
+		_, err = ParsePath(expr)
+		assert.Error(t, err, expr)
+	}
+}
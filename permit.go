@@ -0,0 +1,226 @@
+package qs
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var digitsRegex = regexp.MustCompile(`\A\d+\z`)
+
+// ParameterMissingError is returned by Require when the key is missing or
+// its value is empty.
+type ParameterMissingError struct {
+	Key string
+}
+
+func (e *ParameterMissingError) Error() string {
+	return fmt.Sprintf("Param is missing or the value is empty: %s", e.Key)
+}
+
+// UnpermittedParamsError lists the params dropped by Permit.
+type UnpermittedParamsError struct {
+	Paths []Path
+}
+
+func (e *UnpermittedParamsError) Error() string {
+	paths := make([]string, len(e.Paths))
+
+	for i, path := range e.Paths {
+		paths[i] = path.String()
+	}
+
+	return fmt.Sprintf("Found unpermitted parameters: %s", strings.Join(paths, ", "))
+}
+
+// Require returns the hash at key, like params.require(key) in Rails.
+func (v Values) Require(key string) (Values, error) {
+	value, ok := v[key]
+
+	if !ok || isBlank(value) {
+		return nil, &ParameterMissingError{Key: key}
+	}
+
+	hash, ok := value.(map[string]interface{})
+
+	if !ok {
+		return nil, fmt.Errorf("Expected type 'map[string]interface{}' for key '%s', but got '%T'", key, value)
+	}
+
+	return Values(hash), nil
+}
+
+func isBlank(value interface{}) bool {
+	switch vv := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(vv) == ""
+	case map[string]interface{}:
+		return len(vv) == 0
+	case []interface{}:
+		return len(vv) == 0
+	}
+
+	return false
+}
+
+// Permit returns a copy of v holding only the params allowed by filters,
+// like params.permit in Rails. A string filter allows a scalar value, which
+// is a string, nil or *File. A map[string][]interface{} filter allows a hash,
+// an array of hashes or a hash with numeric keys, each filtered by the
+// nested filters, or an array of scalars when the nested filters are empty:
+//
+//	v.Permit("name", map[string][]interface{}{"tags": {}, "addresses": {"city"}})
+//
+// Dropped params are reported with an *UnpermittedParamsError, which
+// callers may log or ignore since the filtered copy is returned as well.
+func (v Values) Permit(filters ...interface{}) (Values, error) {
+	unpermitted := []Path{}
+	permitted, err := permitHash(v, filters, Path{}, &unpermitted)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(unpermitted) > 0 {
+		sort.Slice(unpermitted, func(i, j int) bool {
+			return unpermitted[i].String() < unpermitted[j].String()
+		})
+
+		return Values(permitted), &UnpermittedParamsError{Paths: unpermitted}
+	}
+
+	return Values(permitted), nil
+}
+
+func isPermittedScalar(value interface{}) bool {
+	switch value.(type) {
+	case nil, string, *File:
+		return true
+	}
+
+	return false
+}
+
+func permitHash(hash map[string]interface{}, filters []interface{}, path Path, unpermitted *[]Path) (map[string]interface{}, error) {
+	permitted := map[string]interface{}{}
+
+	for _, filter := range filters {
+		switch ff := filter.(type) {
+		case string:
+			if value, ok := hash[ff]; ok && isPermittedScalar(value) {
+				permitted[ff] = value
+			}
+
+		case map[string][]interface{}:
+			for k, nested := range ff {
+				value, ok := hash[k]
+
+				if !ok {
+					continue
+				}
+
+				value, ok, err := permitNested(value, nested, path.child(k), unpermitted)
+
+				if err != nil {
+					return nil, err
+				}
+
+				if ok {
+					permitted[k] = value
+				}
+			}
+
+		default:
+			return nil, fmt.Errorf("Unsupported filter type '%T'", filter)
+		}
+	}
+
+	for _, k := range sortedKeys(hash) {
+		if _, ok := permitted[k]; !ok {
+			*unpermitted = append(*unpermitted, path.child(k))
+		}
+	}
+
+	return permitted, nil
+}
+
+func permitNested(value interface{}, filters []interface{}, path Path, unpermitted *[]Path) (interface{}, bool, error) {
+	switch vv := value.(type) {
+	case []interface{}:
+		array := []interface{}{}
+
+		for i, v := range vv {
+			if len(filters) == 0 {
+				if !isPermittedScalar(v) {
+					return nil, false, nil
+				}
+
+				array = append(array, v)
+				continue
+			}
+
+			hash, ok := v.(map[string]interface{})
+
+			if !ok {
+				*unpermitted = append(*unpermitted, path.child(i))
+				continue
+			}
+
+			permitted, err := permitHash(hash, filters, path.child(i), unpermitted)
+
+			if err != nil {
+				return nil, false, err
+			}
+
+			array = append(array, permitted)
+		}
+
+		return array, true, nil
+
+	case map[string]interface{}:
+		if len(filters) == 0 {
+			return nil, false, nil
+		}
+
+		if len(vv) == 0 || !hasNumericKeys(vv) {
+			permitted, err := permitHash(vv, filters, path, unpermitted)
+			return permitted, err == nil, err
+		}
+
+		hash := map[string]interface{}{}
+
+		for _, k := range sortedKeys(vv) {
+			child, ok := vv[k].(map[string]interface{})
+
+			if !ok {
+				*unpermitted = append(*unpermitted, path.child(k))
+				continue
+			}
+
+			permitted, err := permitHash(child, filters, path.child(k), unpermitted)
+
+			if err != nil {
+				return nil, false, err
+			}
+
+			hash[k] = permitted
+		}
+
+		return hash, true, nil
+	}
+
+	return nil, false, nil
+}
+
+func hasNumericKeys(hash map[string]interface{}) bool {
+	for k := range hash {
+		if !digitsRegex.MatchString(k) {
+			return false
+		}
+	}
+
+	return true
+}
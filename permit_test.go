@@ -0,0 +1,102 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequire(t *testing.T) {
+	hash, err := Unmarshal("user[name]=foo&empty=&blank[]=&flag")
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	values := Values(hash)
+
+	user, err := values.Require("user")
+	if assert.NoError(t, err) {
+		assert.Equal(t, user, Values{"name": "foo"})
+	}
+
+	for _, key := range []string{"missing", "empty", "flag"} {
+		_, err = values.Require(key)
+
+		if assert.IsType(t, err, &ParameterMissingError{}) {
+			assert.Equal(t, err.Error(), "Param is missing or the value is empty: "+key)
+		}
+	}
+
+	_, err = values.Require("blank")
+	assert.Error(t, err)
+}
+
+func TestPermit(t *testing.T) {
+	hash, err := Unmarshal("name=foo&admin=1&tags[]=a&tags[]=b&addresses[][city]=Rio&addresses[][zip]=1&addresses[][city]=Recife&profile[bio]=hi&profile[role]=x&nickname[first]=f")
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	permitted, err := Values(hash).Permit("name", "nickname", "missing", map[string][]interface{}{
+		"tags":      {},
+		"addresses": {"city"},
+		"profile":   {"bio"},
+	})
+
+	assert.Equal(t, permitted, Values{
+		"name":      "foo",
+		"tags":      []interface{}{"a", "b"},
+		"addresses": []interface{}{map[string]interface{}{"city": "Rio"}, map[string]interface{}{"city": "Recife"}},
+		"profile":   map[string]interface{}{"bio": "hi"},
+	})
+
+	if assert.IsType(t, err, &UnpermittedParamsError{}) {
+		assert.Equal(t, err.(*UnpermittedParamsError).Paths, []Path{
+			Path{"addresses", 0, "zip"},
+			Path{"admin"},
+			Path{"nickname"},
+			Path{"profile", "role"},
+		})
+		assert.Equal(t, err.Error(), "Found unpermitted parameters: addresses[0][zip], admin, nickname, profile[role]")
+	}
+
+	permitted, err = Values(hash).Permit("name")
+	assert.Error(t, err)
+	assert.Equal(t, permitted, Values{"name": "foo"})
+
+	_, err = Values(hash).Permit(1)
+	assert.Error(t, err)
+}
+
+func TestPermitNested(t *testing.T) {
+	values := Values{
+		"user": map[string]interface{}{
+			"tags":      []interface{}{"a", map[string]interface{}{"b": "c"}},
+			"addresses": map[string]interface{}{"0": map[string]interface{}{"city": "Rio", "zip": "1"}, "1": "x"},
+			"phones":    []interface{}{"1", map[string]interface{}{"number": "2"}},
+		},
+	}
+
+	user, err := values.Require("user")
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	permitted, err := user.Permit(map[string][]interface{}{
+		"tags":      {},
+		"addresses": {"city"},
+		"phones":    {"number"},
+	})
+
+	assert.Equal(t, permitted, Values{
+		"addresses": map[string]interface{}{"0": map[string]interface{}{"city": "Rio"}},
+		"phones":    []interface{}{map[string]interface{}{"number": "2"}},
+	})
+
+	if assert.IsType(t, err, &UnpermittedParamsError{}) {
+		assert.Equal(t, err.Error(), "Found unpermitted parameters: addresses[0][zip], addresses[1], phones[0], tags")
+	}
+}
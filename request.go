@@ -0,0 +1,155 @@
+package qs
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+const (
+	defaultMaxBodySize      = int64(10 << 20)
+	defaultMaxMemory        = int64(32 << 20)
+	defaultMaxMultipartSize = int64(128 << 20)
+)
+
+// RequestDecoder decodes the query string and the form body of HTTP
+// requests. The zero value is ready to use.
+type RequestDecoder struct {
+	// Dialect decodes the query string and urlencoded bodies. Multipart
+	// bodies are only accepted with Rack, whose rules name their parts.
+	// Defaults to Rack.
+	Dialect Dialect
+	// MaxBodySize limits urlencoded bodies. Defaults to 10 MB.
+	MaxBodySize int64
+	// MaxMultipartSize limits multipart bodies, including the files spilled
+	// to disk. Defaults to 128 MB.
+	MaxMultipartSize int64
+	// MaxMemory is the amount of multipart file data kept in memory before
+	// spilling to disk. Defaults to 32 MB.
+	MaxMemory int64
+}
+
+// ParseRequest decodes r with a zero RequestDecoder. As with Decode, the
+// caller must call RemoveFiles on the result.
+func ParseRequest(r *http.Request) (map[string]interface{}, error) {
+	return RequestDecoder{}.Decode(r)
+}
+
+// Decode merges the params of the query string and of urlencoded or
+// multipart bodies, with body params taking precedence like in Rack. Large
+// multipart files are stored in temporary files, so the caller must call
+// RemoveFiles on the result once done with it. Middleware does this after
+// the handler returns.
+func (d RequestDecoder) Decode(r *http.Request) (map[string]interface{}, error) {
+	dialect := d.Dialect
+
+	if dialect == nil {
+		dialect = Rack
+	}
+
+	params, err := dialect.Unmarshal(r.URL.RawQuery)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := d.decodeBody(r, dialect)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range body {
+		params[k] = v
+	}
+
+	return params, nil
+}
+
+func (d RequestDecoder) decodeBody(r *http.Request, dialect Dialect) (map[string]interface{}, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+
+	if contentType == "" {
+		return nil, nil
+	}
+
+	mediaType, parameters, err := mime.ParseMediaType(contentType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		maxBodySize := d.MaxBodySize
+
+		if maxBodySize <= 0 {
+			maxBodySize = defaultMaxBodySize
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if int64(len(body)) > maxBodySize {
+			return nil, fmt.Errorf("Request body exceeds %d bytes", maxBodySize)
+		}
+
+		return dialect.Unmarshal(string(body))
+
+	case "multipart/form-data":
+		if dialect != Rack {
+			return nil, fmt.Errorf("Multipart bodies are not supported by dialect '%T'", dialect)
+		}
+
+		boundary, ok := parameters["boundary"]
+
+		if !ok {
+			return nil, fmt.Errorf("Missing boundary in Content-Type '%s'", contentType)
+		}
+
+		maxMemory := d.MaxMemory
+
+		if maxMemory <= 0 {
+			maxMemory = defaultMaxMemory
+		}
+
+		maxMultipartSize := d.MaxMultipartSize
+
+		if maxMultipartSize <= 0 {
+			maxMultipartSize = defaultMaxMultipartSize
+		}
+
+		body := &countingReader{r: io.LimitReader(r.Body, maxMultipartSize+1)}
+		params, err := UnmarshalMultipart(body, boundary, maxMemory)
+
+		if body.n > maxMultipartSize {
+			RemoveFiles(params)
+			return nil, fmt.Errorf("Request body exceeds %d bytes", maxMultipartSize)
+		}
+
+		return params, err
+	}
+
+	return nil, nil
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,118 @@
+package qs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/users?page=2&filter[name]=foo", nil)
+
+	params, err := ParseRequest(r)
+	if assert.NoError(t, err) {
+		assert.Equal(t, params, map[string]interface{}{"page": "2", "filter": map[string]interface{}{"name": "foo"}})
+	}
+
+	r = httptest.NewRequest("POST", "/users?page=2&user[name]=foo", strings.NewReader("user[name]=bar&user[tags][]=a"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	params, err = ParseRequest(r)
+	if assert.NoError(t, err) {
+		assert.Equal(t, params, map[string]interface{}{"page": "2", "user": map[string]interface{}{"name": "bar", "tags": []interface{}{"a"}}})
+	}
+
+	r = httptest.NewRequest("POST", "/users", strings.NewReader(`{"user":"foo"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	params, err = ParseRequest(r)
+	if assert.NoError(t, err) {
+		assert.Equal(t, params, map[string]interface{}{})
+	}
+}
+
+func TestParseRequestMultipart(t *testing.T) {
+	body, contentType, err := MarshalMultipart(map[string]interface{}{
+		"user": map[string]interface{}{"name": "bar"},
+	})
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	r := httptest.NewRequest("POST", "/users?page=1", body)
+	r.Header.Set("Content-Type", contentType)
+
+	params, err := ParseRequest(r)
+	if assert.NoError(t, err) {
+		assert.Equal(t, params, map[string]interface{}{"page": "1", "user": map[string]interface{}{"name": "bar"}})
+	}
+
+	r = httptest.NewRequest("POST", "/users", strings.NewReader(""))
+	r.Header.Set("Content-Type", "multipart/form-data")
+
+	_, err = ParseRequest(r)
+	assert.Error(t, err)
+}
+
+func TestRequestDecoder(t *testing.T) {
+	decoder := RequestDecoder{Dialect: Node, MaxBodySize: 8}
+
+	r := httptest.NewRequest("POST", "/users?ids[0]=1&ids[1]=2", strings.NewReader("a=b"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	params, err := decoder.Decode(r)
+	if assert.NoError(t, err) {
+		assert.Equal(t, params, map[string]interface{}{"ids": []interface{}{"1", "2"}, "a": "b"})
+	}
+
+	r = httptest.NewRequest("POST", "/users", strings.NewReader("a=123456789"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	_, err = decoder.Decode(r)
+	assert.Error(t, err)
+
+	r = httptest.NewRequest("GET", "/users?x[y]=1&x[]=2", nil)
+
+	_, err = decoder.Decode(&http.Request{URL: r.URL})
+	assert.NoError(t, err)
+
+	_, err = RequestDecoder{}.Decode(r)
+	assert.Error(t, err)
+}
+
+func TestRequestDecoderMultipart(t *testing.T) {
+	body, contentType, err := MarshalMultipart(map[string]interface{}{"a": "123456789"})
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	r := httptest.NewRequest("POST", "/users", strings.NewReader(body.String()))
+	r.Header.Set("Content-Type", contentType)
+
+	_, err = RequestDecoder{MaxMultipartSize: int64(body.Len() - 1)}.Decode(r)
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), fmt.Sprintf("Request body exceeds %d bytes", body.Len()-1))
+	}
+
+	r = httptest.NewRequest("POST", "/users", strings.NewReader(body.String()))
+	r.Header.Set("Content-Type", contentType)
+
+	params, err := RequestDecoder{MaxBodySize: 1, MaxMultipartSize: int64(body.Len())}.Decode(r)
+	if assert.NoError(t, err) {
+		assert.Equal(t, params, map[string]interface{}{"a": "123456789"})
+	}
+
+	r = httptest.NewRequest("POST", "/users", strings.NewReader(body.String()))
+	r.Header.Set("Content-Type", contentType)
+
+	_, err = RequestDecoder{Dialect: Node}.Decode(r)
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "Multipart bodies are not supported by dialect 'qs.NodeDialect'")
+	}
+}
@@ -0,0 +1,130 @@
+package qs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("Invalid signature")
+	ErrExpiredSignature = errors.New("Signature expired")
+)
+
+var now = time.Now
+
+// KeyRing holds HMAC keys for signed query strings. The first key signs and
+// every key verifies, so keys can be rotated by prepending a new one and
+// dropping the oldest once its links have expired.
+type KeyRing [][]byte
+
+// Sign encodes params canonically with an expires param and an HMAC-SHA256
+// signature param computed with key.
+func Sign(params map[string]interface{}, key []byte, expires time.Time) (string, error) {
+	return KeyRing{key}.Sign(params, expires)
+}
+
+// Verify checks a query string produced by Sign and returns its params
+// without expires and signature.
+func Verify(qs string, key []byte) (map[string]interface{}, error) {
+	return KeyRing{key}.Verify(qs)
+}
+
+func (k KeyRing) Sign(params map[string]interface{}, expires time.Time) (string, error) {
+	if len(k) == 0 {
+		return "", fmt.Errorf("Key ring is empty")
+	}
+
+	signed := map[string]interface{}{}
+
+	for key, value := range params {
+		if key == "expires" || key == "signature" {
+			return "", fmt.Errorf("Key '%s' is reserved for signed query strings", key)
+		}
+
+		signed[key] = value
+	}
+
+	signed["expires"] = strconv.FormatInt(expires.Unix(), 10)
+
+	canonical, err := CanonicalizeParams(signed)
+
+	if err != nil {
+		return "", err
+	}
+
+	signature := base64.RawURLEncoding.EncodeToString(computeSignature(k[0], canonical))
+
+	return canonical + "&signature=" + signature, nil
+}
+
+func (k KeyRing) Verify(qs string) (map[string]interface{}, error) {
+	params, err := Unmarshal(qs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, ok := params["signature"].(string)
+
+	if !ok {
+		return nil, ErrInvalidSignature
+	}
+
+	delete(params, "signature")
+
+	signature, err := base64.RawURLEncoding.DecodeString(encoded)
+
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	canonical, err := CanonicalizeParams(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	valid := false
+
+	for _, key := range k {
+		if hmac.Equal(computeSignature(key, canonical), signature) {
+			valid = true
+		}
+	}
+
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+
+	expires, ok := params["expires"].(string)
+
+	if !ok {
+		return nil, ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(expires, 10, 64)
+
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	if !now().Before(time.Unix(seconds, 0)) {
+		return nil, ErrExpiredSignature
+	}
+
+	delete(params, "expires")
+
+	return params, nil
+}
+
+func computeSignature(key []byte, canonical string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(canonical))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,102 @@
+package qs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	key := []byte("secret")
+	expires := time.Now().Add(time.Hour)
+	params := map[string]interface{}{
+		"file": "report.pdf",
+		"user": map[string]interface{}{"id": "7", "lists": []interface{}{"news", "offers"}},
+	}
+
+	signed, err := Sign(params, key, expires)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.True(t, strings.HasPrefix(signed, "expires="))
+	assert.Contains(t, signed, "&signature=")
+
+	verified, err := Verify(signed, key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, verified, params)
+	}
+
+	reordered := strings.Replace(signed, "file=report.pdf&", "", 1) + "&file=report.pdf"
+
+	_, err = Verify(reordered, key)
+	assert.NoError(t, err)
+
+	_, err = Verify(signed, []byte("other"))
+	assert.Equal(t, err, ErrInvalidSignature)
+
+	_, err = Verify(strings.Replace(signed, "report.pdf", "secret.pdf", 1), key)
+	assert.Equal(t, err, ErrInvalidSignature)
+
+	_, err = Verify(strings.Replace(signed, "signature=", "signature=x", 1), key)
+	assert.Equal(t, err, ErrInvalidSignature)
+
+	_, err = Verify("file=report.pdf", key)
+	assert.Equal(t, err, ErrInvalidSignature)
+
+	_, err = Sign(map[string]interface{}{"expires": "1"}, key, expires)
+	assert.Error(t, err)
+}
+
+func TestVerifyExpired(t *testing.T) {
+	defer func() { now = time.Now }()
+
+	key := []byte("secret")
+	signed, err := Sign(map[string]interface{}{"a": "b"}, key, time.Unix(1000, 0))
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	now = func() time.Time { return time.Unix(999, 0) }
+
+	_, err = Verify(signed, key)
+	assert.NoError(t, err)
+
+	now = func() time.Time { return time.Unix(1000, 0) }
+
+	_, err = Verify(signed, key)
+	assert.Equal(t, err, ErrExpiredSignature)
+
+	_, err = Verify(strings.Replace(signed, "expires=1000", "expires=2000", 1), key)
+	assert.Equal(t, err, ErrInvalidSignature)
+}
+
+func TestKeyRing(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	old := KeyRing{[]byte("old")}
+	rotated := KeyRing{[]byte("new"), []byte("old")}
+
+	signed, err := old.Sign(map[string]interface{}{"a": "b"}, expires)
+
+	if assert.NoError(t, err) {
+		_, err = rotated.Verify(signed)
+		assert.NoError(t, err)
+	}
+
+	signed, err = rotated.Sign(map[string]interface{}{"a": "b"}, expires)
+
+	if assert.NoError(t, err) {
+		_, err = Verify(signed, []byte("new"))
+		assert.NoError(t, err)
+
+		_, err = old.Verify(signed)
+		assert.Equal(t, err, ErrInvalidSignature)
+	}
+
+	_, err = KeyRing{}.Sign(map[string]interface{}{}, expires)
+	assert.Error(t, err)
+}
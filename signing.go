@@ -0,0 +1,94 @@
+package qs
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// OAuthParameterString returns the normalized request parameters of OAuth
+// 1.0a (RFC 5849, section 3.4.1.3.2). Nested params are flattened to the
+// bracket names Marshal writes, then names and values are percent-encoded
+// and sorted. params must hold the query, body and oauth_ parameters,
+// without oauth_signature.
+func OAuthParameterString(params map[string]interface{}) (string, error) {
+	return sortedEncodedQuery(params)
+}
+
+// OAuthBaseString returns the OAuth 1.0a signature base string for a request
+// to u, whose query is ignored in favor of params.
+func OAuthBaseString(method string, u *url.URL, params map[string]interface{}) (string, error) {
+	parameters, err := OAuthParameterString(params)
+
+	if err != nil {
+		return "", err
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+
+	if port := u.Port(); port != "" && !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+
+	path := u.EscapedPath()
+
+	if path == "" {
+		path = "/"
+	}
+
+	parts := []string{
+		escapeRFC3986(strings.ToUpper(method)),
+		escapeRFC3986(scheme + "://" + host + path),
+		escapeRFC3986(parameters),
+	}
+
+	return strings.Join(parts, "&"), nil
+}
+
+// SigV4CanonicalQuery returns the canonical query string of AWS Signature
+// Version 4, with nested params flattened to the bracket names Marshal
+// writes.
+func SigV4CanonicalQuery(params map[string]interface{}) (string, error) {
+	return sortedEncodedQuery(params)
+}
+
+func sortedEncodedQuery(params map[string]interface{}) (string, error) {
+	pairs := [][2]string{}
+
+	err := flattenParams(params, "", func(key string, value interface{}) error {
+		str := ""
+
+		switch vv := value.(type) {
+		case nil:
+		case string:
+			str = vv
+		default:
+			return fmt.Errorf("Unsupported type '%T' for key '%s'", value, key)
+		}
+
+		pairs = append(pairs, [2]string{escapeRFC3986(key), escapeRFC3986(str)})
+		return nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+
+		return pairs[i][1] < pairs[j][1]
+	})
+
+	components := make([]string, len(pairs))
+
+	for i, pair := range pairs {
+		components[i] = pair[0] + "=" + pair[1]
+	}
+
+	return strings.Join(components, "&"), nil
+}
@@ -0,0 +1,68 @@
+package qs
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOAuthBaseString(t *testing.T) {
+	// Adapted from RFC 5849, section 3.4.1, without the repeated a3 which
+	// cannot be expressed as a nested param.
+	params := map[string]interface{}{
+		"b5":                     "=%3D",
+		"a3":                     "2 q",
+		"c@":                     "",
+		"a2":                     "r b",
+		"oauth_consumer_key":     "9djdj82h48djs9d2",
+		"oauth_token":            "kkk9d7dh3k39sjv7",
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        "137131201",
+		"oauth_nonce":            "7d8f3e4a",
+		"c2":                     nil,
+	}
+
+	u, _ := url.Parse("HTTP://EXAMPLE.COM:80/request?b5=ignored")
+
+	base, err := OAuthBaseString("post", u, params)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, base, "POST&http%3A%2F%2Fexample.com%2Frequest&a2%3Dr%2520b%26a3%3D2%2520q%26b5%3D%253D%25253D%26c%2540%3D%26c2%3D%26oauth_consumer_key%3D9djdj82h48djs9d2%26oauth_nonce%3D7d8f3e4a%26oauth_signature_method%3DHMAC-SHA1%26oauth_timestamp%3D137131201%26oauth_token%3Dkkk9d7dh3k39sjv7")
+	}
+
+	u, _ = url.Parse("https://api.example.com:8443")
+
+	base, err = OAuthBaseString("GET", u, map[string]interface{}{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, base, "GET&https%3A%2F%2Fapi.example.com%3A8443%2F&")
+	}
+}
+
+func TestOAuthParameterString(t *testing.T) {
+	parameters, err := OAuthParameterString(map[string]interface{}{
+		"user":   map[string]interface{}{"name": "Jo Doe", "tags": []interface{}{"b", "a"}},
+		"oauth_": "x",
+		"flag":   nil,
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, parameters, "flag=&oauth_=x&user%5Bname%5D=Jo%20Doe&user%5Btags%5D%5B%5D=a&user%5Btags%5D%5B%5D=b")
+	}
+
+	_, err = OAuthParameterString(map[string]interface{}{"a": 1})
+	assert.Error(t, err)
+}
+
+func TestSigV4CanonicalQuery(t *testing.T) {
+	query, err := SigV4CanonicalQuery(map[string]interface{}{
+		"Action":  "ListUsers",
+		"Version": "2010-05-08",
+		"Marker":  "a/b~c d",
+		"Filter":  map[string]interface{}{"Name": []interface{}{"z", "y"}},
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, query, "Action=ListUsers&Filter%5BName%5D%5B%5D=y&Filter%5BName%5D%5B%5D=z&Marker=a%2Fb~c%20d&Version=2010-05-08")
+	}
+}
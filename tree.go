@@ -0,0 +1,196 @@
+package qs
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Component is a single component of a query string. RawKey and RawValue
+// keep the original bytes, including their percent-encoding, and Delimiter
+// is the delimiter that follows the component, empty for the last one.
+type Component struct {
+	RawKey    string
+	RawValue  string
+	HasValue  bool
+	Delimiter string
+
+	// Segments is the bracket path of the unescaped key, where x[y][] is
+	// []string{"x", "y", ""}.
+	Segments []string
+}
+
+// Tree is a lossless syntax tree of a query string. Serializing it writes
+// untouched components back byte for byte, including empty ones.
+type Tree struct {
+	Components []*Component
+}
+
+// ParseTree splits raw into components on &, like Unmarshal.
+func ParseTree(raw string) *Tree {
+	return ParseTreeDelimiters(raw, "&")
+}
+
+// ParseTreeDelimiters splits raw into components on any of the bytes in
+// delimiters, such as "&;" for servers that also accept ; between
+// components.
+func ParseTreeDelimiters(raw string, delimiters string) *Tree {
+	tree := &Tree{Components: []*Component{}}
+
+	if raw == "" {
+		return tree
+	}
+
+	for {
+		end := strings.IndexAny(raw, delimiters)
+		part := raw
+		component := &Component{}
+
+		if end != -1 {
+			part = raw[:end]
+			component.Delimiter = raw[end : end+1]
+		}
+
+		tuple := strings.SplitN(part, "=", 2)
+		component.RawKey = tuple[0]
+
+		if len(tuple) > 1 {
+			component.RawValue = tuple[1]
+			component.HasValue = true
+		}
+
+		component.Segments = splitSegments(component.Key())
+		tree.Components = append(tree.Components, component)
+
+		if end == -1 {
+			return tree
+		}
+
+		raw = raw[end+1:]
+	}
+}
+
+func splitSegments(key string) []string {
+	pos := strings.IndexByte(key, '[')
+
+	if pos == -1 {
+		return []string{key}
+	}
+
+	segments := []string{key[:pos]}
+
+	for _, match := range nodeBracketRegex.FindAllString(key[pos:], -1) {
+		segments = append(segments, match[1:len(match)-1])
+	}
+
+	return segments
+}
+
+func (c *Component) String() string {
+	if c.HasValue {
+		return c.RawKey + "=" + c.RawValue
+	}
+
+	return c.RawKey
+}
+
+// Key returns the unescaped key.
+func (c *Component) Key() string {
+	return queryUnescape(c.RawKey)
+}
+
+// Value returns the unescaped value and whether the component has one.
+func (c *Component) Value() (string, bool) {
+	return queryUnescape(c.RawValue), c.HasValue
+}
+
+// SetKey replaces the key, escaping it like Marshal does.
+func (c *Component) SetKey(key string) {
+	c.RawKey = escapeKey(key)
+	c.Segments = splitSegments(key)
+}
+
+// SetValue replaces the value, escaping it with url.QueryEscape.
+func (c *Component) SetValue(value string) {
+	c.RawValue = url.QueryEscape(value)
+	c.HasValue = true
+}
+
+// escapeKey escapes key while keeping its brackets readable.
+func escapeKey(key string) string {
+	return strings.NewReplacer("%5B", "[", "%5D", "]").Replace(url.QueryEscape(key))
+}
+
+func (t *Tree) String() string {
+	var b strings.Builder
+
+	for _, c := range t.Components {
+		b.WriteString(c.String())
+		b.WriteString(c.Delimiter)
+	}
+
+	return b.String()
+}
+
+// Find returns the components whose unescaped key equals key.
+func (t *Tree) Find(key string) []*Component {
+	found := []*Component{}
+
+	for _, c := range t.Components {
+		if c.Key() == key {
+			found = append(found, c)
+		}
+	}
+
+	return found
+}
+
+// Append adds a component at the end, separated with & from the previous
+// one.
+func (t *Tree) Append(key, value string) *Component {
+	component := &Component{}
+	component.SetKey(key)
+	component.SetValue(value)
+
+	if n := len(t.Components); n > 0 && t.Components[n-1].Delimiter == "" {
+		t.Components[n-1].Delimiter = "&"
+	}
+
+	t.Components = append(t.Components, component)
+	return component
+}
+
+// Remove deletes component along with one of the delimiters around it.
+func (t *Tree) Remove(component *Component) {
+	for i, c := range t.Components {
+		if c != component {
+			continue
+		}
+
+		if c.Delimiter == "" && i > 0 {
+			t.Components[i-1].Delimiter = ""
+		}
+
+		t.Components = append(t.Components[:i:i], t.Components[i+1:]...)
+		return
+	}
+}
+
+// Params decodes the components of the tree, nesting their keys with the
+// same rules as Unmarshal.
+func (t *Tree) Params() (map[string]interface{}, error) {
+	params := map[string]interface{}{}
+
+	for _, c := range t.Components {
+		value := interface{}(nil)
+
+		if v, ok := c.Value(); ok {
+			value = v
+		}
+
+		if err := normalizeParams(params, c.Key(), value); err != nil {
+			return nil, err
+		}
+	}
+
+	return params, nil
+}
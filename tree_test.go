@@ -0,0 +1,88 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTree(t *testing.T) {
+	raws := []string{
+		"",
+		"a",
+		"a=",
+		"&",
+		"a=1&&b=%7e;c=d+e&",
+		"x%5By%5D%5B%5D=1&x[y][]=%41",
+	}
+
+	for _, raw := range raws {
+		assert.Equal(t, ParseTree(raw).String(), raw)
+		assert.Equal(t, ParseTreeDelimiters(raw, "&;").String(), raw)
+	}
+
+	tree := ParseTreeDelimiters("a=1&&x%5By%5D[]=%41;flag", "&;")
+
+	if assert.Len(t, tree.Components, 4) {
+		assert.Equal(t, *tree.Components[0], Component{RawKey: "a", RawValue: "1", HasValue: true, Delimiter: "&", Segments: []string{"a"}})
+		assert.Equal(t, *tree.Components[1], Component{Delimiter: "&", Segments: []string{""}})
+		assert.Equal(t, *tree.Components[2], Component{RawKey: "x%5By%5D[]", RawValue: "%41", HasValue: true, Delimiter: ";", Segments: []string{"x", "y", ""}})
+		assert.Equal(t, *tree.Components[3], Component{RawKey: "flag", Segments: []string{"flag"}})
+
+		value, ok := tree.Components[2].Value()
+		assert.True(t, ok)
+		assert.Equal(t, value, "A")
+		assert.Equal(t, tree.Components[2].Key(), "x[y][]")
+
+		_, ok = tree.Components[3].Value()
+		assert.False(t, ok)
+	}
+
+	params, err := tree.Params()
+	if assert.NoError(t, err) {
+		assert.Equal(t, params, map[string]interface{}{"a": "1", "x": map[string]interface{}{"y": []interface{}{"A"}}, "flag": nil})
+	}
+
+	_, err = ParseTree("x[y]=1&x[]=2").Params()
+	assert.Error(t, err)
+}
+
+func TestTreeRewrite(t *testing.T) {
+	tree := ParseTreeDelimiters("utm_source=a%20b&&page=1;sort=name&sig=%2Fx", "&;")
+
+	for _, c := range tree.Find("page") {
+		c.SetValue("2 3")
+	}
+
+	assert.Equal(t, tree.String(), "utm_source=a%20b&&page=2+3;sort=name&sig=%2Fx")
+
+	tree.Find("sig")[0].SetKey("filter[owner id]")
+	assert.Equal(t, tree.String(), "utm_source=a%20b&&page=2+3;sort=name&filter[owner+id]=%2Fx")
+	assert.Equal(t, tree.Components[4].Segments, []string{"filter", "owner id"})
+
+	tree.Remove(tree.Find("utm_source")[0])
+	assert.Equal(t, tree.String(), "&page=2+3;sort=name&filter[owner+id]=%2Fx")
+
+	tree.Remove(tree.Find("filter[owner id]")[0])
+	assert.Equal(t, tree.String(), "&page=2+3;sort=name")
+
+	tree.Append("tags[]", "a&b")
+	assert.Equal(t, tree.String(), "&page=2+3;sort=name&tags[]=a%26b")
+
+	tree.Remove(&Component{})
+	assert.Equal(t, tree.String(), "&page=2+3;sort=name&tags[]=a%26b")
+
+	semicolons := ParseTree("q=a;b&x=1")
+
+	params, err := semicolons.Params()
+	if assert.NoError(t, err) {
+		assert.Equal(t, params, map[string]interface{}{"q": "a;b", "x": "1"})
+	}
+
+	semicolons.Find("q")[0].SetValue("z")
+	assert.Equal(t, semicolons.String(), "q=z&x=1")
+
+	empty := ParseTree("")
+	empty.Append("a", "b")
+	assert.Equal(t, empty.String(), "a=b")
+}
@@ -12,9 +12,18 @@ var objectRegex1 = regexp.MustCompile(`^\[\]\[([^\[\]]+)\]$`)
 var objectRegex2 = regexp.MustCompile(`^\[\](.+)$`)
 
 func Unmarshal(qs string) (map[string]interface{}, error) {
-	components := strings.Split(qs, "&")
 	params := map[string]interface{}{}
 
+	if err := unmarshalInto(qs, plainHash(params), plainHashKind); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+func unmarshalInto(qs string, params paramsHash, kind hashKind) error {
+	components := strings.Split(qs, "&")
+
 	for _, c := range components {
 
 		tuple := strings.SplitN(c, "=", 2)
@@ -36,15 +45,59 @@ func Unmarshal(qs string) (map[string]interface{}, error) {
 			value = tuple[1]
 		}
 
-		if err := normalizeParams(params, key, value); err != nil {
-			return nil, err
+		if err := normalizeHash(params, key, value, kind); err != nil {
+			return err
 		}
 	}
 
-	return params, nil
+	return nil
+}
+
+// paramsHash is a hash built by normalizeHash.
+type paramsHash interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{})
+}
+
+// hashKind tells normalizeHash how to create hashes and recognize the ones
+// already stored in the tree.
+type hashKind struct {
+	name   string
+	empty  func() paramsHash
+	wrap   func(value interface{}) (paramsHash, bool)
+	unwrap func(hash paramsHash) interface{}
+}
+
+type plainHash map[string]interface{}
+
+func (h plainHash) Get(key string) (interface{}, bool) {
+	value, ok := h[key]
+	return value, ok
+}
+
+func (h plainHash) Set(key string, value interface{}) {
+	h[key] = value
+}
+
+var plainHashKind = hashKind{
+	name: "map[string]interface{}",
+	empty: func() paramsHash {
+		return plainHash{}
+	},
+	wrap: func(value interface{}) (paramsHash, bool) {
+		hash, ok := value.(map[string]interface{})
+		return plainHash(hash), ok
+	},
+	unwrap: func(hash paramsHash) interface{} {
+		return map[string]interface{}(hash.(plainHash))
+	},
 }
 
 func normalizeParams(params map[string]interface{}, key string, value interface{}) error {
+	return normalizeHash(plainHash(params), key, value, plainHashKind)
+}
+
+func normalizeHash(params paramsHash, key string, value interface{}, kind hashKind) error {
 	after := ""
 
 	if pos := nameRegex.FindIndex([]byte(key)); len(pos) == 2 {
@@ -58,15 +111,15 @@ func normalizeParams(params map[string]interface{}, key string, value interface{
 
 	k := matches[1]
 	if after == "" {
-		params[k] = value
+		params.Set(k, value)
 		return nil
 	}
 
 	if after == "[]" {
-		ival, ok := params[k]
+		ival, ok := params.Get(k)
 
 		if !ok {
-			params[k] = []interface{}{value}
+			params.Set(k, []interface{}{value})
 			return nil
 		}
 
@@ -76,7 +129,7 @@ func normalizeParams(params map[string]interface{}, key string, value interface{
 			return fmt.Errorf("Expected type '[]interface{}' for key '%s', but got '%T'", k, ival)
 		}
 
-		params[k] = append(array, value)
+		params.Set(k, append(array, value))
 		return nil
 	}
 
@@ -93,11 +146,11 @@ func normalizeParams(params map[string]interface{}, key string, value interface{
 		}
 
 		if childKey != "" {
-			ival, ok := params[k]
+			ival, ok := params.Get(k)
 
 			if !ok {
-				params[k] = []interface{}{}
-				ival = params[k]
+				params.Set(k, []interface{}{})
+				ival, _ = params.Get(k)
 			}
 
 			array, ok := ival.([]interface{})
@@ -107,36 +160,36 @@ func normalizeParams(params map[string]interface{}, key string, value interface{
 			}
 
 			if length := len(array); length > 0 {
-				if hash, ok := array[length-1].(map[string]interface{}); ok {
-					if _, ok := hash[childKey]; !ok {
-						normalizeParams(hash, childKey, value)
+				if hash, ok := kind.wrap(array[length-1]); ok {
+					if _, ok := hash.Get(childKey); !ok {
+						normalizeHash(hash, childKey, value, kind)
 						return nil
 					}
 				}
 			}
 
-			newHash := map[string]interface{}{}
-			normalizeParams(newHash, childKey, value)
-			params[k] = append(array, newHash)
+			newHash := kind.empty()
+			normalizeHash(newHash, childKey, value, kind)
+			params.Set(k, append(array, kind.unwrap(newHash)))
 
 			return nil
 		}
 	}
 
-	ival, ok := params[k]
+	ival, ok := params.Get(k)
 
 	if !ok {
-		params[k] = map[string]interface{}{}
-		ival = params[k]
+		params.Set(k, kind.unwrap(kind.empty()))
+		ival, _ = params.Get(k)
 	}
 
-	hash, ok := ival.(map[string]interface{})
+	hash, ok := kind.wrap(ival)
 
 	if !ok {
-		return fmt.Errorf("Expected type 'map[string]interface{}' for key '%s', but got '%T'", k, ival)
+		return fmt.Errorf("Expected type '%s' for key '%s', but got '%T'", kind.name, k, ival)
 	}
 
-	if err := normalizeParams(hash, after, value); err != nil {
+	if err := normalizeHash(hash, after, value, kind); err != nil {
 		return err
 	}
 
@@ -0,0 +1,146 @@
+package qs
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// MergeURL returns a copy of u whose query string is the deep merge of the
+// current nested query and patch. Hashes are merged recursively, any other
+// value replaces the existing one and nil deletes the key.
+func MergeURL(u *url.URL, patch map[string]interface{}) (*url.URL, error) {
+	params, err := Unmarshal(u.RawQuery)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Merge(params, patch, MergeOptions{Conflicts: ConflictOverride, NilDeletes: true}); err != nil {
+		return nil, err
+	}
+
+	query, err := Marshal(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	merged := *u
+	merged.RawQuery = query
+
+	return &merged, nil
+}
+
+// FromValues nests the bracketed keys of values with the same rules as
+// Unmarshal. Since url.Values does not keep the order of different keys,
+// values are applied round-robin across keys in sorted order, which rebuilds
+// arrays of hashes whose members carry the same keys. Use
+// FromValuesWithKeys when the original order is known.
+func FromValues(values url.Values) (map[string]interface{}, error) {
+	params := map[string]interface{}{}
+
+	if err := applyValues(params, values); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+func applyValues(params map[string]interface{}, values url.Values) error {
+	keys := make([]string, 0, len(values))
+	length := 0
+
+	for k, v := range values {
+		keys = append(keys, k)
+
+		if len(v) > length {
+			length = len(v)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for i := 0; i < length; i++ {
+		for _, k := range keys {
+			if i >= len(values[k]) {
+				continue
+			}
+
+			if err := normalizeParams(params, k, values[k][i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// FromValuesWithKeys is like FromValues but applies values in the order of
+// keys, which lists the key of every value as it appeared in the original
+// query, such as the keys of a Tree's components or the keys returned by
+// ToValuesWithKeys. The n-th occurrence of a key takes its n-th value.
+// Values left over are applied afterwards as FromValues does.
+func FromValuesWithKeys(values url.Values, keys []string) (map[string]interface{}, error) {
+	params := map[string]interface{}{}
+	remaining := url.Values{}
+
+	for k, v := range values {
+		remaining[k] = v
+	}
+
+	for _, k := range keys {
+		v := remaining[k]
+
+		if len(v) == 0 {
+			continue
+		}
+
+		if err := normalizeParams(params, k, v[0]); err != nil {
+			return nil, err
+		}
+
+		remaining[k] = v[1:]
+	}
+
+	if err := applyValues(params, remaining); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// ToValues flattens hash into bracketed keys, as Marshal writes them. Nil
+// values become empty strings, so they decode back as "" rather than nil.
+func ToValues(hash map[string]interface{}) (url.Values, error) {
+	values, _, err := ToValuesWithKeys(hash)
+	return values, err
+}
+
+// ToValuesWithKeys is like ToValues but also returns the key of every value
+// in the order Marshal writes them. Passing both to FromValuesWithKeys
+// rebuilds hash, apart from nil values.
+func ToValuesWithKeys(hash map[string]interface{}) (url.Values, []string, error) {
+	values := url.Values{}
+	keys := []string{}
+
+	err := flattenParams(hash, "", func(key string, value interface{}) error {
+		switch vv := value.(type) {
+		case nil:
+			values.Add(key, "")
+		case string:
+			values.Add(key, vv)
+		default:
+			return fmt.Errorf("Unsupported type '%T' for key '%s'", value, key)
+		}
+
+		keys = append(keys, key)
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return values, keys, nil
+}
@@ -0,0 +1,147 @@
+package qs
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeURL(t *testing.T) {
+	u, _ := url.Parse("https://example.com/items?page=2&filter[status]=open&filter[tags][]=a&sort=name#results")
+
+	merged, err := MergeURL(u, map[string]interface{}{
+		"page":   nil,
+		"filter": map[string]interface{}{"tags": []interface{}{"b", "c"}, "owner": map[string]interface{}{"id": "7", "name": nil}},
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, merged.Scheme+"://"+merged.Host+merged.Path+"#"+merged.Fragment, "https://example.com/items#results")
+
+		params, err := Unmarshal(merged.RawQuery)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, params, map[string]interface{}{
+				"filter": map[string]interface{}{
+					"status": "open",
+					"tags":   []interface{}{"b", "c"},
+					"owner":  map[string]interface{}{"id": "7"},
+				},
+				"sort": "name",
+			})
+		}
+	}
+
+	assert.Equal(t, u.RawQuery, "page=2&filter[status]=open&filter[tags][]=a&sort=name")
+
+	merged, err = MergeURL(u, map[string]interface{}{"sort": map[string]interface{}{"by": "name"}})
+	if assert.NoError(t, err) {
+		params, err := Unmarshal(merged.RawQuery)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, params["sort"], map[string]interface{}{"by": "name"})
+		}
+	}
+
+	u, _ = url.Parse("/items?x[y]=1&x[]=2")
+
+	_, err = MergeURL(u, map[string]interface{}{})
+	assert.Error(t, err)
+}
+
+func TestFromValues(t *testing.T) {
+	hash, err := FromValues(url.Values{
+		"foo":        []string{"bar"},
+		"baz[]":      []string{"1", "2"},
+		"x[y][][z]":  []string{"1", "2"},
+		"x[y][][w]":  []string{"a", "3"},
+		"pid=1234":   []string{"1023"},
+		"my[nested]": []string{""},
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, map[string]interface{}{
+			"foo":      "bar",
+			"baz":      []interface{}{"1", "2"},
+			"x":        map[string]interface{}{"y": []interface{}{map[string]interface{}{"z": "1", "w": "a"}, map[string]interface{}{"z": "2", "w": "3"}}},
+			"pid=1234": "1023",
+			"my":       map[string]interface{}{"nested": ""},
+		})
+	}
+
+	_, err = FromValues(url.Values{"x[y]": []string{"1"}, "x[]": []string{"2"}})
+	assert.Error(t, err)
+}
+
+func TestToValues(t *testing.T) {
+	hash := map[string]interface{}{
+		"foo": "bar",
+		"x":   map[string]interface{}{"y": []interface{}{map[string]interface{}{"z": "1", "w": "a"}, map[string]interface{}{"z": "2", "w": "3"}}},
+		"tag": []interface{}{"a b", "c"},
+	}
+
+	values, err := ToValues(hash)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, values, url.Values{
+			"foo":       []string{"bar"},
+			"x[y][][w]": []string{"a", "3"},
+			"x[y][][z]": []string{"1", "2"},
+			"tag[]":     []string{"a b", "c"},
+		})
+
+		decoded, err := FromValues(values)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, decoded, hash)
+		}
+	}
+
+	values, err = ToValues(map[string]interface{}{"foo": nil})
+	if assert.NoError(t, err) {
+		assert.Equal(t, values, url.Values{"foo": []string{""}})
+	}
+
+	_, err = ToValues(map[string]interface{}{"foo": 1})
+	assert.Error(t, err)
+}
+
+func TestFromValuesWithKeys(t *testing.T) {
+	hash, err := Unmarshal("items[][a]=1&items[][a]=2&items[][b]=x")
+	assert.NoError(t, err)
+
+	values, keys, err := ToValuesWithKeys(hash)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, keys, []string{"items[][a]", "items[][a]", "items[][b]"})
+
+		decoded, err := FromValuesWithKeys(values, keys)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, decoded, map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"a": "1"},
+				map[string]interface{}{"a": "2", "b": "x"},
+			}})
+		}
+	}
+
+	tree := ParseTree("b[][y]=1&b[][x]=2&a=3")
+	keys = []string{}
+
+	for _, c := range tree.Components {
+		keys = append(keys, c.Key())
+	}
+
+	decoded, err := FromValuesWithKeys(url.Values{"a": {"3", "4"}, "b[][x]": {"2"}, "b[][y]": {"1"}, "c": {"5"}}, keys)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, decoded, map[string]interface{}{
+			"a": "4",
+			"b": []interface{}{map[string]interface{}{"y": "1", "x": "2"}},
+			"c": "5",
+		})
+	}
+
+	_, err = FromValuesWithKeys(url.Values{"x[y]": {"1"}, "x[]": {"2"}}, []string{"x[y]", "x[]"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,306 @@
+package qs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A Constraint checks the value found at a path. present is false when the
+// path does not exist in the tree.
+type Constraint func(value interface{}, present bool) error
+
+// Rules maps path patterns to constraints. Patterns are bracket paths in
+// which [] matches every member of an array, as in items[][qty].
+type Rules map[string][]Constraint
+
+// Violation is a failed constraint at the concrete Path of a value.
+type Violation struct {
+	Path    Path
+	Message string
+}
+
+// ValidationErrors holds every violation found by Validate.
+type ValidationErrors []Violation
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+
+	for i, violation := range e {
+		messages[i] = violation.Path.String() + " " + violation.Message
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Validate checks params against every rule and returns ValidationErrors
+// listing all violations, or nil when there are none.
+func (r Rules) Validate(params map[string]interface{}) error {
+	patterns := make([]string, 0, len(r))
+
+	for pattern := range r {
+		patterns = append(patterns, pattern)
+	}
+
+	sort.Strings(patterns)
+
+	violations := ValidationErrors{}
+
+	for _, pattern := range patterns {
+		path, err := parsePath(pattern, true)
+
+		if err != nil {
+			return err
+		}
+
+		expandPath(params, path, Path{}, func(path Path, value interface{}, present bool) {
+			for _, constraint := range r[pattern] {
+				err := constraint(value, present)
+
+				if err == nil {
+					continue
+				}
+
+				violations = append(violations, Violation{Path: path, Message: err.Error()})
+
+				if _, ok := err.(typeError); ok {
+					break
+				}
+			}
+		})
+	}
+
+	if len(violations) > 0 {
+		return violations
+	}
+
+	return nil
+}
+
+// expandPath calls fn for every value matching pattern. A missing value is
+// reported with the rest of the pattern appended to its path, unless that
+// rest matches array members, of which there are none.
+func expandPath(node interface{}, pattern Path, path Path, fn func(path Path, value interface{}, present bool)) {
+	if len(path) == len(pattern) {
+		fn(path, node, true)
+		return
+	}
+
+	switch key := hashElement(node, pattern[len(path)]).(type) {
+	case string:
+		if hash, ok := node.(map[string]interface{}); ok {
+			if value, ok := hash[key]; ok {
+				expandPath(value, pattern, path.child(pattern[len(path)]), fn)
+				return
+			}
+		}
+
+	case int:
+		if array, ok := node.([]interface{}); ok && key < len(array) {
+			expandPath(array[key], pattern, path.child(key), fn)
+			return
+		}
+
+	case wildcard:
+		if array, ok := node.([]interface{}); ok {
+			for i, value := range array {
+				expandPath(value, pattern, path.child(i), fn)
+			}
+		}
+
+		return
+	}
+
+	for _, element := range pattern[len(path):] {
+		if _, ok := element.(wildcard); ok {
+			return
+		}
+	}
+
+	fn(append(path, pattern[len(path):]...), nil, false)
+}
+
+func isPresent(value interface{}, present bool) bool {
+	return present && value != nil
+}
+
+// Required rejects missing, nil and blank values.
+func Required() Constraint {
+	return func(value interface{}, present bool) error {
+		if !present || isBlank(value) {
+			return errors.New("is required")
+		}
+
+		return nil
+	}
+}
+
+// typeError is returned by constraints that check the type of a value.
+// Validate skips the remaining constraints of a path once one fails.
+type typeError string
+
+func (e typeError) Error() string {
+	return string(e)
+}
+
+func typeConstraint(message string, check func(string) bool) Constraint {
+	constraint := stringConstraint(message, check)
+
+	return func(value interface{}, present bool) error {
+		if err := constraint(value, present); err != nil {
+			return typeError(message)
+		}
+
+		return nil
+	}
+}
+
+func stringConstraint(message string, check func(string) bool) Constraint {
+	return func(value interface{}, present bool) error {
+		if !isPresent(value, present) {
+			return nil
+		}
+
+		if str, ok := value.(string); !ok || !check(str) {
+			return errors.New(message)
+		}
+
+		return nil
+	}
+}
+
+// Int accepts base 10 integers.
+func Int() Constraint {
+	return typeConstraint("must be an integer", func(s string) bool {
+		_, err := strconv.ParseInt(s, 10, 64)
+		return err == nil
+	})
+}
+
+// Bool accepts the values understood by strconv.ParseBool.
+func Bool() Constraint {
+	return typeConstraint("must be a boolean", func(s string) bool {
+		_, err := strconv.ParseBool(s)
+		return err == nil
+	})
+}
+
+// Date accepts times in the given time.Parse layout.
+func Date(layout string) Constraint {
+	return typeConstraint("must be a date formatted as "+layout, func(s string) bool {
+		_, err := time.Parse(layout, s)
+		return err == nil
+	})
+}
+
+// Match accepts strings matching re.
+func Match(re *regexp.Regexp) Constraint {
+	return stringConstraint("must match "+re.String(), re.MatchString)
+}
+
+// OneOf accepts one of the given strings.
+func OneOf(values ...string) Constraint {
+	return stringConstraint("must be one of "+strings.Join(values, ", "), func(s string) bool {
+		for _, v := range values {
+			if s == v {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+func numberConstraint(check func(float64) error) Constraint {
+	return func(value interface{}, present bool) error {
+		if !isPresent(value, present) {
+			return nil
+		}
+
+		str, ok := value.(string)
+
+		if !ok {
+			return typeError("must be a number")
+		}
+
+		n, err := strconv.ParseFloat(str, 64)
+
+		if err != nil {
+			return typeError("must be a number")
+		}
+
+		return check(n)
+	}
+}
+
+// Min accepts numbers greater than or equal to min.
+func Min(min float64) Constraint {
+	return numberConstraint(func(n float64) error {
+		if n < min {
+			return fmt.Errorf("must be at least %v", min)
+		}
+
+		return nil
+	})
+}
+
+// Max accepts numbers less than or equal to max.
+func Max(max float64) Constraint {
+	return numberConstraint(func(n float64) error {
+		if n > max {
+			return fmt.Errorf("must be at most %v", max)
+		}
+
+		return nil
+	})
+}
+
+func length(value interface{}) (int, bool) {
+	switch vv := value.(type) {
+	case string:
+		return utf8.RuneCountInString(vv), true
+	case []interface{}:
+		return len(vv), true
+	case map[string]interface{}:
+		return len(vv), true
+	}
+
+	return 0, false
+}
+
+// MinLength accepts strings with at least min characters, and arrays or
+// hashes with at least min members.
+func MinLength(min int) Constraint {
+	return func(value interface{}, present bool) error {
+		if !isPresent(value, present) {
+			return nil
+		}
+
+		if n, ok := length(value); !ok || n < min {
+			return fmt.Errorf("must have a length of at least %d", min)
+		}
+
+		return nil
+	}
+}
+
+// MaxLength accepts strings with at most max characters, and arrays or
+// hashes with at most max members.
+func MaxLength(max int) Constraint {
+	return func(value interface{}, present bool) error {
+		if !isPresent(value, present) {
+			return nil
+		}
+
+		if n, ok := length(value); !ok || n > max {
+			return fmt.Errorf("must have a length of at most %d", max)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+package qs
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	rules := Rules{
+		"filter[status]":   {Required(), OneOf("open", "closed")},
+		"filter[since]":    {Date("2006-01-02")},
+		"filter[archived]": {Bool()},
+		"page":             {Int(), Min(1)},
+		"items[][qty]":     {Required(), Int(), Min(1), Max(10)},
+		"items[][sku]":     {Match(regexp.MustCompile(`\A[A-Z]{3}\d+\z`))},
+		"tags":             {MaxLength(2)},
+		"q":                {MinLength(2), MaxLength(5)},
+	}
+
+	hash, err := Unmarshal("filter[status]=open&filter[since]=2016-11-20&filter[archived]=false&page=2&items[][qty]=1&items[][sku]=ABC1&items[][qty]=10&tags[]=a&q=go")
+
+	if assert.NoError(t, err) {
+		assert.NoError(t, rules.Validate(hash))
+	}
+
+	hash, err = Unmarshal("filter[status]=pending&filter[since]=yesterday&filter[archived]=maybe&page=0&items[][qty]=0&items[][sku]=abc&items[][sku]=ABC2&tags[]=a&tags[]=b&tags[]=c&q=golang")
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	err = rules.Validate(hash)
+
+	if assert.IsType(t, err, ValidationErrors{}) {
+		assert.Equal(t, err.(ValidationErrors), ValidationErrors{
+			Violation{Path{"filter", "archived"}, "must be a boolean"},
+			Violation{Path{"filter", "since"}, "must be a date formatted as 2006-01-02"},
+			Violation{Path{"filter", "status"}, "must be one of open, closed"},
+			Violation{Path{"items", 0, "qty"}, "must be at least 1"},
+			Violation{Path{"items", 1, "qty"}, "is required"},
+			Violation{Path{"items", 0, "sku"}, `must match \A[A-Z]{3}\d+\z`},
+			Violation{Path{"page"}, "must be at least 1"},
+			Violation{Path{"q"}, "must have a length of at most 5"},
+			Violation{Path{"tags"}, "must have a length of at most 2"},
+		})
+	}
+
+	err = rules.Validate(map[string]interface{}{"page": "x", "q": "a"})
+
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "filter[status] is required; page must be an integer; q must have a length of at least 2")
+	}
+
+	err = Rules{"items[][qty]": {Required()}}.Validate(map[string]interface{}{"items": []interface{}{"x"}})
+
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "items[0][qty] is required")
+	}
+
+	err = Rules{"a[": {Required()}}.Validate(map[string]interface{}{})
+	assert.Error(t, err)
+}
+
+func TestValidateIndexedHash(t *testing.T) {
+	hash, err := Unmarshal("user[addresses][0][city]=Paris&user[addresses][1][zip]=69001")
+	assert.NoError(t, err)
+
+	err = Rules{
+		"user[addresses][0][city]": {Required()},
+		"user[addresses][1][city]": {Required()},
+	}.Validate(hash)
+
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "user[addresses][1][city] is required")
+	}
+}
+
+func TestValidateStopsAfterTypeError(t *testing.T) {
+	err := Rules{
+		"page": {Min(1), Int(), Max(10)},
+		"q":    {MinLength(3), Match(regexp.MustCompile(`\A\d+\z`))},
+	}.Validate(map[string]interface{}{"page": "x", "q": "a"})
+
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), "page must be a number; q must have a length of at least 3; q must match \\A\\d+\\z")
+	}
+}
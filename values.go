@@ -0,0 +1,102 @@
+package qs
+
+import (
+	"fmt"
+)
+
+// Values wraps a tree returned by Unmarshal with accessors taking bracket
+// path expressions such as user[addresses][0][city].
+type Values map[string]interface{}
+
+// Get returns the value at path, or nil when it does not exist.
+func (v Values) Get(path string) interface{} {
+	value, _ := v.lookup(path)
+	return value
+}
+
+// Has reports whether a value exists at path.
+func (v Values) Has(path string) bool {
+	_, ok := v.lookup(path)
+	return ok
+}
+
+func (v Values) lookup(expr string) (interface{}, bool) {
+	path, err := ParsePath(expr)
+
+	if err != nil {
+		return nil, false
+	}
+
+	return lookupPath(map[string]interface{}(v), path)
+}
+
+// Set stores value at path, creating intermediate hashes. An index equal to
+// the length of an array appends to it.
+func (v Values) Set(path string, value interface{}) error {
+	p, err := ParsePath(path)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = setPath(map[string]interface{}(v), p, 0, value)
+	return err
+}
+
+// Append adds value to the array at path, creating it when missing.
+func (v Values) Append(path string, value interface{}) error {
+	p, err := ParsePath(path)
+
+	if err != nil {
+		return err
+	}
+
+	current, ok := lookupPath(map[string]interface{}(v), p)
+
+	if !ok || current == nil {
+		current = []interface{}{}
+	}
+
+	array, ok := current.([]interface{})
+
+	if !ok {
+		return fmt.Errorf("Expected type '[]interface{}' for key '%s', but got '%T'", p, current)
+	}
+
+	_, err = setPath(map[string]interface{}(v), p, 0, append(array, value))
+	return err
+}
+
+// Del removes the value at path. Removing an array member shifts the
+// following members down.
+func (v Values) Del(path string) {
+	p, err := ParsePath(path)
+
+	if err != nil {
+		return
+	}
+
+	parent, ok := lookupPath(map[string]interface{}(v), p[:len(p)-1])
+
+	if !ok {
+		return
+	}
+
+	switch key := hashElement(parent, p[len(p)-1]).(type) {
+	case string:
+		if hash, ok := parent.(map[string]interface{}); ok {
+			delete(hash, key)
+		}
+
+	case int:
+		if array, ok := parent.([]interface{}); ok && key < len(array) {
+			array = append(array[:key:key], array[key+1:]...)
+			setPath(map[string]interface{}(v), p[:len(p)-1], 0, array)
+		}
+	}
+}
+
+// Encode marshals the tree with Marshal.
+func (v Values) Encode() (string, error) {
+	return Marshal(v)
+}
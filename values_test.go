@@ -0,0 +1,121 @@
+package qs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValuesGet(t *testing.T) {
+	hash, err := Unmarshal("user[name]=foo&user[addresses][][city]=Rio&user[addresses][][city]=Recife&flag")
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	values := Values(hash)
+
+	assert.Equal(t, values.Get("user[name]"), "foo")
+	assert.Equal(t, values.Get("user[addresses][1][city]"), "Recife")
+	assert.Equal(t, values.Get("user[addresses][0]"), map[string]interface{}{"city": "Rio"})
+	assert.Nil(t, values.Get("user[addresses][2][city]"))
+	assert.Nil(t, values.Get("user[name][first]"))
+	assert.Nil(t, values.Get("user[]"))
+
+	assert.True(t, values.Has("flag"))
+	assert.True(t, values.Has("user[addresses][1]"))
+	assert.False(t, values.Has("user[age]"))
+}
+
+func TestValuesSet(t *testing.T) {
+	values := Values{}
+
+	assert.NoError(t, values.Set("user[name]", "foo"))
+	assert.NoError(t, values.Set("user[addresses][0][city]", "Rio"))
+	assert.NoError(t, values.Set("user[addresses][1][city]", "Recife"))
+	assert.NoError(t, values.Set("user[addresses][0][city]", "Sao Paulo"))
+
+	assert.Equal(t, values, Values{"user": map[string]interface{}{
+		"name": "foo",
+		"addresses": []interface{}{
+			map[string]interface{}{"city": "Sao Paulo"},
+			map[string]interface{}{"city": "Recife"},
+		},
+	}})
+
+	assert.Error(t, values.Set("user[addresses][3]", "x"))
+	assert.Error(t, values.Set("user[name][first]", "x"))
+	assert.NoError(t, values.Set("user[0]", "x"))
+	assert.Equal(t, values.Get("user[0]"), "x")
+	assert.Error(t, values.Set("user[", "x"))
+}
+
+func TestValuesAppend(t *testing.T) {
+	values := Values{"name": "foo"}
+
+	assert.NoError(t, values.Append("tags", "a"))
+	assert.NoError(t, values.Append("tags", "b"))
+	assert.NoError(t, values.Append("filter[ids]", "1"))
+	assert.Error(t, values.Append("name", "x"))
+
+	assert.Equal(t, values, Values{
+		"name":   "foo",
+		"tags":   []interface{}{"a", "b"},
+		"filter": map[string]interface{}{"ids": []interface{}{"1"}},
+	})
+}
+
+func TestValuesDel(t *testing.T) {
+	values := Values{
+		"name": "foo",
+		"tags": []interface{}{"a", "b", "c"},
+		"user": map[string]interface{}{"age": "30", "city": "Rio"},
+	}
+
+	values.Del("tags[1]")
+	values.Del("user[age]")
+	values.Del("name")
+	values.Del("missing[key]")
+	values.Del("tags[5]")
+
+	assert.Equal(t, values, Values{
+		"tags": []interface{}{"a", "c"},
+		"user": map[string]interface{}{"city": "Rio"},
+	})
+}
+
+func TestValuesEncode(t *testing.T) {
+	values := Values{}
+
+	assert.NoError(t, values.Set("user[tags][0]", "a"))
+
+	querystring, err := values.Encode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, querystring, "user[tags][]=a")
+	}
+}
+
+func TestValuesIndexedHash(t *testing.T) {
+	hash, err := Unmarshal("user[addresses][0][city]=Paris&user[addresses][1][city]=Lyon")
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	values := Values(hash)
+
+	assert.Equal(t, values.Get("user[addresses][0][city]"), "Paris")
+	assert.True(t, values.Has("user[addresses][1]"))
+	assert.False(t, values.Has("user[addresses][2]"))
+
+	assert.NoError(t, values.Set("user[addresses][0][zip]", "75001"))
+	assert.Equal(t, values.Get("user[addresses]"), map[string]interface{}{
+		"0": map[string]interface{}{"city": "Paris", "zip": "75001"},
+		"1": map[string]interface{}{"city": "Lyon"},
+	})
+
+	values.Del("user[addresses][1]")
+	assert.Equal(t, values.Get("user[addresses]"), map[string]interface{}{
+		"0": map[string]interface{}{"city": "Paris", "zip": "75001"},
+	})
+}
@@ -0,0 +1,64 @@
+package qs
+
+import "errors"
+
+// SkipSubtree is returned by a WalkFunc to skip the children of the value
+// being visited.
+var SkipSubtree = errors.New("skip this subtree")
+
+// SkipAll is returned by a WalkFunc to stop walking without an error.
+var SkipAll = errors.New("skip everything")
+
+// WalkFunc is called by Walk for every value in a tree.
+type WalkFunc func(path Path, value interface{}) error
+
+// Walk calls fn for every hash, array and leaf of tree, starting with tree
+// itself at an empty path. Hash keys are visited in sorted order, or in
+// insertion order for an OrderedMap. Any error other than SkipSubtree and
+// SkipAll stops the walk and is returned.
+func Walk(tree interface{}, fn WalkFunc) error {
+	err := walk(Path{}, tree, fn)
+
+	if err == SkipAll || err == SkipSubtree {
+		return nil
+	}
+
+	return err
+}
+
+func walk(path Path, value interface{}, fn WalkFunc) error {
+	if err := fn(path, value); err != nil {
+		if err == SkipSubtree {
+			return nil
+		}
+
+		return err
+	}
+
+	switch vv := value.(type) {
+	case map[string]interface{}:
+		for _, k := range sortedKeys(vv) {
+			if err := walk(path.child(k), vv[k], fn); err != nil {
+				return err
+			}
+		}
+
+	case *OrderedMap:
+		for _, k := range vv.Keys() {
+			v, _ := vv.Get(k)
+
+			if err := walk(path.child(k), v, fn); err != nil {
+				return err
+			}
+		}
+
+	case []interface{}:
+		for i, v := range vv {
+			if err := walk(path.child(i), v, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
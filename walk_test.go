@@ -0,0 +1,109 @@
+package qs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	hash, err := Unmarshal("user[name]=bob&user[password]=secret&tags[]=a&tags[]=b&q")
+	assert.NoError(t, err)
+
+	visited := []string{}
+
+	err = Walk(hash, func(path Path, value interface{}) error {
+		visited = append(visited, path.String())
+		return nil
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, visited, []string{"", "q", "tags", "tags[0]", "tags[1]", "user", "user[name]", "user[password]"})
+	}
+
+	err = Walk(hash, func(path Path, value interface{}) error {
+		if hash, ok := value.(map[string]interface{}); ok {
+			if _, ok := hash["password"]; ok {
+				hash["password"] = "[FILTERED]"
+			}
+		}
+
+		return nil
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash["user"], map[string]interface{}{"name": "bob", "password": "[FILTERED]"})
+	}
+}
+
+func TestWalkSkip(t *testing.T) {
+	hash, err := Unmarshal("a[b]=1&a[c]=2&d=3&e=4")
+	assert.NoError(t, err)
+
+	visited := []string{}
+
+	err = Walk(hash, func(path Path, value interface{}) error {
+		visited = append(visited, path.String())
+
+		if path.String() == "a" {
+			return SkipSubtree
+		}
+
+		return nil
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, visited, []string{"", "a", "d", "e"})
+	}
+
+	visited = []string{}
+
+	err = Walk(hash, func(path Path, value interface{}) error {
+		visited = append(visited, path.String())
+
+		if path.String() == "a[b]" {
+			return SkipAll
+		}
+
+		return nil
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, visited, []string{"", "a", "a[b]"})
+	}
+
+	stop := errors.New("stop")
+
+	err = Walk(hash, func(path Path, value interface{}) error {
+		if path.String() == "d" {
+			return stop
+		}
+
+		return nil
+	})
+
+	assert.Equal(t, err, stop)
+
+	err = Walk("leaf", func(path Path, value interface{}) error {
+		return SkipSubtree
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestWalkOrdered(t *testing.T) {
+	m, err := UnmarshalOrdered("z=1&a[y]=2&a[x]=3")
+	assert.NoError(t, err)
+
+	visited := []string{}
+
+	err = Walk(m, func(path Path, value interface{}) error {
+		visited = append(visited, path.String())
+		return nil
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, visited, []string{"", "z", "a", "a[y]", "a[x]"})
+	}
+}